@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// adviceSteps are the lap time differences used to show the effect of a new fastest or slowest lap.
+var adviceSteps = []time.Duration{
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// adviseRivals is the quantity of positions either side of the driver to compare against.
+const adviseRivals = 2
+
+// advise prints the current Percentage for the driver with raceNumber, the effect of a new fastest or slowest lap during the next run,
+// and the lap times that break even against nearby rivals ahead and behind.
+func advise(event *Event, raceNumber string) {
+	drivers := event.Drivers
+	i := indexOfRacingNum(drivers, raceNumber)
+	if i < 0 {
+		if j := indexOfRacingNum(event.NotClassified, raceNumber); j >= 0 {
+			fmt.Printf("%s %s is Not Classified: %s\n", raceNumber, event.NotClassified[j].Name, event.NotClassified[j].Reason)
+			return
+		}
+		fmt.Println("No results found for racing number", raceNumber)
+		return
+	}
+
+	d := &drivers[i]
+	fmt.Printf("%s %s %s %s%s\n", d.Ordinal(), d.RaceNumber, d.Name, hPercentage, formatScore(d))
+	fmt.Printf("%s %v    %s %v    %s %v    %s %d    %s %d\n\n", hQualify, d.Qualify, hFastest, d.Fastest, hSlowest, d.Slowest, hRuns, d.Runs, hLaps, d.Laps)
+	fmt.Printf("New laps between %v and %v won't change the %s.\n\n", d.Fastest, d.Slowest, hPercentage)
+
+	fmt.Printf("%-16s    %-11s    %-16s    %-11s\n", "New "+hFastest, hPercentage, "New "+hSlowest, hPercentage)
+	for _, step := range adviceSteps {
+		fastest, slowest := d.Fastest-step, d.Slowest+step
+		_, fastestPct := percentage(fastest, d.Slowest, d.Qualify)
+		_, slowestPct := percentage(d.Fastest, slowest, d.Qualify)
+		fmt.Printf("%-16v    %11.8f    %-16v    %11.8f\n", fastest, fastestPct*d.Handicap, slowest, slowestPct*d.Handicap)
+	}
+
+	fmt.Printf("\n%-5s  %4s %-*s  %-11s    %-19s    %-19s\n", hPosition, hRacingNumber, event.LongestNameLen, hDriver, hPercentage, "Break-even "+hFastest, "Break-even "+hSlowest)
+	for r := i - adviseRivals; r <= i+adviseRivals; r++ {
+		if r < 0 || r == i || r >= len(drivers) {
+			continue
+		}
+
+		rival := &drivers[r]
+		fastest, slowest := breakEven(d, rival)
+		fmt.Printf("%-5s  %4s %-*s  %11.8f    %-19s    %-19s\n", rival.Ordinal(), rival.RaceNumber, event.LongestNameLen, rival.Name, rival.Score, fastest, slowest)
+	}
+
+	fmt.Printf("\nAgainst a rival behind, the break-even lap times are yours: to stay ahead, keep the new fastest lap no quicker than the break-even fastest lap and the new slowest lap no slower than the break-even slowest lap."+
+		"\nAgainst a rival ahead, the break-even lap times are the rival's: you catch the rival if they set a new fastest lap quicker than the break-even fastest lap or a new slowest lap slower than the break-even slowest lap."+
+		"\nCompleting more runs than a rival places you ahead regardless of %s.\n", hPercentage)
+}
+
+// breakEven returns the break-even fastest and slowest lap times between the driver and a rival, or "-" when lap times alone can't swap their positions.
+// New laps can only lower a score, so the lap times are those of whichever driver is ahead, beyond which they'd drop behind the other.
+func breakEven(d, rival *Driver) (fastest, slowest string) {
+	fastest, slowest = "-", "-"
+	// A rival that completed a different quantity of runs can't be passed or lost to by lap times alone.
+	if rival.Runs != d.Runs || rival.Score == d.Score {
+		return fastest, slowest
+	}
+
+	ahead, behind := d, rival
+	if rival.Score > d.Score {
+		ahead, behind = rival, d
+	}
+	f, s := ahead.lapsToBeat(behind.Score)
+	if f > 0 && f < ahead.Fastest {
+		fastest = f.String()
+	}
+	if s > ahead.Slowest {
+		slowest = s.String()
+	}
+
+	return fastest, slowest
+}
+
+// formatScore returns the driver's Percentage, including the handicapped score when handicaps are used.
+func formatScore(d *Driver) string {
+	if cfg.hasHandicaps() {
+		return fmt.Sprintf(" %.8f    %s %.8f", d.Percentage, hScore, d.Score)
+	}
+	return fmt.Sprintf(" %.8f", d.Percentage)
+}
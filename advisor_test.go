@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdvise(t *testing.T) {
+	drivers := []Driver{
+		{RaceNumber: "1", Name: "Ann", Fastest: 65 * time.Second, Slowest: 66 * time.Second, Qualify: 66 * time.Second, Runs: 2},
+		{RaceNumber: "2", Name: "Bob", Fastest: 64 * time.Second, Slowest: 66 * time.Second, Qualify: 66 * time.Second, Runs: 2},
+		{RaceNumber: "3", Name: "Cal", Fastest: 63 * time.Second, Slowest: 67 * time.Second, Qualify: 66 * time.Second, Runs: 2},
+		{RaceNumber: "4", Name: "Dee", Fastest: 60 * time.Second, Slowest: 70 * time.Second, Qualify: 66 * time.Second, Runs: 2},
+	}
+	for i := range drivers {
+		d := &drivers[i]
+		d.SlowAv, d.Percentage = percentage(d.Fastest, d.Slowest, d.Qualify)
+		d.Handicap, d.Score, d.Position = 1, d.Percentage, uint(i)+1
+	}
+	event := Event{Drivers: drivers, NotClassified: []Driver{{RaceNumber: "7", Name: "Eve", Reason: "No qualifying lap time"}}, LongestNameLen: 3}
+
+	tests := []struct {
+		raceNumber string
+		want       []string
+	}{
+		{"2", []string{
+			"2nd 2 Bob Percentage 96.96969697",
+			fmt.Sprintf("%-16v    %11.8f", 63*time.Second, 95.45454545),
+			// Ann's break-even lap times are her own, beyond which Bob catches her.
+			fmt.Sprintf("1st       1 Ann  %11.8f    %-19v    %-19v", drivers[0].Score, 64000100*time.Microsecond, 68062400*time.Microsecond),
+			fmt.Sprintf("3rd       3 Cal  %11.8f    %-19v    %-19v", drivers[2].Score, 62526400*time.Microsecond, 69111100*time.Microsecond),
+			fmt.Sprintf("4th       4 Dee  %11.8f    %-19v    %-19v", drivers[3].Score, 58235300*time.Microsecond, 79066600*time.Microsecond),
+		}},
+		{"7", []string{"7 Eve is Not Classified: No qualifying lap time"}},
+		{"99", []string{"No results found for racing number 99"}},
+	}
+
+	for _, test := range tests {
+		out := captureStdout(t, func() { advise(&event, test.raceNumber) })
+		for _, want := range test.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("advise(%s) = \n%s\nwant it to contain %q", test.raceNumber, out, want)
+			}
+		}
+	}
+}
+
+func TestBreakEven(t *testing.T) {
+	driver := func(fastest, slowest float64, runs uint) Driver {
+		d := Driver{Fastest: secondsToDuration(fastest), Slowest: secondsToDuration(slowest), Qualify: 66 * time.Second, Runs: runs, Handicap: 1}
+		d.SlowAv, d.Percentage = percentage(d.Fastest, d.Slowest, d.Qualify)
+		d.Score = d.Percentage
+		return d
+	}
+	bob := driver(64, 66, 2)
+
+	tests := []struct {
+		name             string
+		rival            Driver
+		fastest, slowest string
+	}{
+		// Behind Bob, the break-even lap times are Bob's.
+		{"behind", driver(63, 67, 2), "1m2.5264s", "1m9.1111s"},
+		// Ahead of Bob, the break-even lap times are the rival's.
+		{"ahead", driver(65, 66, 2), "1m4.0001s", "1m8.0624s"},
+		{"equal", driver(64, 66, 2), "-", "-"},
+		{"more runs", driver(65, 66, 3), "-", "-"},
+		{"fewer runs", driver(63, 67, 1), "-", "-"},
+	}
+
+	for _, test := range tests {
+		fastest, slowest := breakEven(&bob, &test.rival)
+		if fastest != test.fastest || slowest != test.slowest {
+			t.Errorf("%s: breakEven() = %s, %s, want %s, %s", test.name, fastest, slowest, test.fastest, test.slowest)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"time"
+)
+
+// needed returns the lap time as text, or a hyphen when not possible.
+func needed(lapTime time.Duration) string {
+	if lapTime <= 0 {
+		return "-"
+	}
+	return lapTime.String()
+}
+
+// Analysis describes how far a driver is behind the driver ahead and the leader, and what lap times would have lifted them one position.
+type Analysis struct {
+	Driver    *Driver
+	Ahead     *Driver       // The nearest driver with a better position.
+	GapAhead  float64       // Score difference to the driver ahead.
+	GapLeader float64       // Score difference to the leader.
+	Fastest   time.Duration // Fastest lap time (or slower) required to pass the driver ahead, zero when not possible.
+	Slowest   time.Duration // Slowest lap time (or quicker) required to pass the driver ahead, zero when not possible.
+}
+
+// analyse returns the gap analysis for each classified driver behind the leader, given drivers are already ranked.
+func analyse(drivers []Driver) (analyses []Analysis) {
+	for i := range drivers {
+		d := &drivers[i]
+
+		// Find the nearest driver with a better position.
+		ahead := -1
+		for j := i - 1; j >= 0; j-- {
+			if drivers[j].Position < d.Position {
+				ahead = j
+				break
+			}
+		}
+		if ahead < 0 {
+			continue
+		}
+
+		a := Analysis{
+			Driver:    d,
+			Ahead:     &drivers[ahead],
+			GapAhead:  drivers[ahead].Score - d.Score,
+			GapLeader: drivers[0].Score - d.Score,
+		}
+
+		// Drivers are sorted by the quantity of runs completed first, so a better lap time can't overcome fewer runs.
+		if a.Ahead.Runs == d.Runs && d.Handicap > 0 {
+			a.Fastest, a.Slowest = d.lapsToBeat(a.Ahead.Score)
+		}
+
+		analyses = append(analyses, a)
+	}
+
+	return analyses
+}
+
+// lapsToBeat returns the fastest lap time, or the slowest lap time, required for the driver to score more than target.
+// The percentage increases as the fastest lap time approaches the average of the slowest and qualifying lap times,
+// so a slower fastest lap or a quicker slowest lap is required. Zero is returned when a lap time isn't possible.
+func (driver *Driver) lapsToBeat(target float64) (fastest, slowest time.Duration) {
+	// Rearrange the percentage formula: Score = Fastest ÷ ((Slowest + Qualify) ÷ 2) × 100 × Handicap.
+	pct := target / driver.Handicap
+	// A target without a score, like a driver ahead without a qualifying lap time, has no lap time to aim for.
+	if pct <= 0 {
+		return 0, 0
+	}
+	slowAv, _ := percentage(driver.Fastest, driver.Slowest, driver.Qualify)
+
+	//nolint:gomnd // Ignore hardcoded numbers
+	fastest = slowerThan(pct / 100 * slowAv)
+	// The fastest lap can't be slower than the slowest or qualifying lap.
+	if fastest > driver.Slowest || fastest > driver.Qualify {
+		fastest = 0
+	}
+
+	//nolint:gomnd // Ignore hardcoded numbers
+	slowest = fasterThan(driver.Fastest.Seconds()*100/pct*2 - driver.Qualify.Seconds())
+	// The slowest lap can't be quicker than the fastest lap.
+	if slowest < driver.Fastest {
+		slowest = 0
+	}
+
+	return fastest, slowest
+}
+
+// fasterThan returns the slowest lap time, to the same precision as Natsoft lap times, that is quicker than seconds.
+func fasterThan(seconds float64) time.Duration {
+	d := time.Duration(seconds * float64(time.Second)).Truncate(lapPrecision)
+	if d.Seconds() >= seconds {
+		d -= lapPrecision
+	}
+	return d
+}
+
+// slowerThan returns the quickest lap time, to the same precision as Natsoft lap times, that is slower than seconds.
+func slowerThan(seconds float64) time.Duration {
+	d := time.Duration(seconds * float64(time.Second)).Truncate(lapPrecision)
+	if d.Seconds() <= seconds {
+		d += lapPrecision
+	}
+	return d
+}
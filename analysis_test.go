@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLapsToBeat(t *testing.T) {
+	driver := Driver{Fastest: 64 * time.Second, Slowest: 68 * time.Second, Qualify: 66 * time.Second, Handicap: 1}
+	_, current := percentage(driver.Fastest, driver.Slowest, driver.Qualify)
+
+	tests := []struct {
+		target       float64
+		fastest      bool // A fastest lap time is possible.
+		slowest      bool // A slowest lap time is possible.
+		zeroExpected bool
+	}{
+		{target: current + 0.3713, fastest: true, slowest: true},
+		{target: current + 1.1309, fastest: true, slowest: true},
+		{target: 99.9, fastest: false, slowest: false}, // Requires a fastest lap slower than the slowest lap.
+		{target: 0, zeroExpected: true},                // The driver ahead has no score.
+		{target: -1, zeroExpected: true},
+	}
+
+	for _, test := range tests {
+		fastest, slowest := driver.lapsToBeat(test.target)
+		if test.zeroExpected {
+			if fastest != 0 || slowest != 0 {
+				t.Errorf("target %.3f: got %s and %s, want zeros", test.target, fastest, slowest)
+			}
+			continue
+		}
+
+		if (fastest > 0) != test.fastest || (slowest > 0) != test.slowest {
+			t.Errorf("target %.3f: got fastest %s and slowest %s, want fastest possible %v and slowest possible %v", test.target, fastest, slowest, test.fastest, test.slowest)
+			continue
+		}
+
+		// The lap time found beats the target, and a lap one step slower (or quicker) doesn't.
+		if fastest > 0 {
+			if _, pct := percentage(fastest, driver.Slowest, driver.Qualify); pct <= test.target {
+				t.Errorf("target %.3f: fastest %s scores %.4f", test.target, fastest, pct)
+			}
+			if _, pct := percentage(fastest-lapPrecision, driver.Slowest, driver.Qualify); pct > test.target {
+				t.Errorf("target %.3f: fastest %s isn't the slowest fastest lap possible", test.target, fastest)
+			}
+		}
+		if slowest > 0 {
+			if _, pct := percentage(driver.Fastest, slowest, driver.Qualify); pct <= test.target {
+				t.Errorf("target %.3f: slowest %s scores %.4f", test.target, slowest, pct)
+			}
+			if _, pct := percentage(driver.Fastest, slowest+lapPrecision, driver.Qualify); pct > test.target {
+				t.Errorf("target %.3f: slowest %s isn't the slowest lap possible", test.target, slowest)
+			}
+		}
+	}
+}
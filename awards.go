@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Award is an extra prize calculated from the classified drivers results.
+type Award struct {
+	Heading string
+	Winners []string // Racing number and name of each winner, more than one when tied.
+	Detail  string   // The winning result.
+}
+
+// awardRule calculates an award by comparing a value for each eligible driver.
+type awardRule struct {
+	heading string
+	lowest  bool                            // The lowest value wins, otherwise the highest value wins.
+	value   func(d *Driver) (float64, bool) // Returns false when the driver isn't eligible for the award.
+	detail  func(value float64) string
+}
+
+// awardRules contains the awards available, keyed by the name used in the `awards` setting.
+var awardRules = map[string]awardRule{
+	"fastest": {
+		heading: "Fastest Lap:",
+		lowest:  true,
+		value: func(d *Driver) (float64, bool) {
+			return d.Fastest.Seconds(), d.Fastest > 0
+		},
+		detail: func(value float64) string {
+			return secondsToDuration(value).String()
+		},
+	},
+	"laps": {
+		heading: "Most Laps:",
+		value: func(d *Driver) (float64, bool) {
+			return float64(d.Laps), d.Laps >= 1
+		},
+		detail: func(value float64) string {
+			return fmt.Sprintf("%.0f laps", value)
+		},
+	},
+	"consistent": {
+		heading: "Most Consistent:",
+		lowest:  true,
+		value: func(d *Driver) (float64, bool) {
+			return d.CV, d.StdDev > 0
+		},
+		detail: func(value float64) string {
+			return fmt.Sprintf("CV %.2f%%", value)
+		},
+	},
+	"improved": {
+		heading: "Most Improved:",
+		value: func(d *Driver) (float64, bool) {
+			improvement := d.Qualify - d.Fastest
+			return improvement.Seconds(), d.Qualify > 0 && d.Fastest > 0 && improvement > 0
+		},
+		detail: func(value float64) string {
+			return fmt.Sprintf("%s faster than qualifying", secondsToDuration(value))
+		},
+	},
+}
+
+// awardNames returns the names of all awards available.
+func awardNames() string {
+	names := make([]string, 0, len(awardRules))
+	for name := range awardRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// calcAwards returns the awards listed in the `awards` setting, in the same order.
+func calcAwards(drivers []Driver) (awards []Award) {
+	for _, name := range cfg.Awards {
+		rule, ok := awardRules[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			fmt.Println("Unknown award:", name)
+			continue
+		}
+
+		if award, ok := rule.calc(drivers); ok {
+			awards = append(awards, award)
+		}
+	}
+
+	return awards
+}
+
+// calc returns the award given to the drivers with the best value, or false if no drivers are eligible.
+func (rule *awardRule) calc(drivers []Driver) (award Award, ok bool) {
+	var best float64
+	for i := range drivers {
+		value, eligible := rule.value(&drivers[i])
+		if !eligible {
+			continue
+		}
+
+		winner := fmt.Sprintf("%s %s", drivers[i].RaceNumber, drivers[i].Name)
+		switch {
+		case !ok || rule.lowest && value < best || !rule.lowest && value > best:
+			best = value
+			award.Winners = []string{winner}
+			ok = true
+		case value == best:
+			award.Winners = append(award.Winners, winner)
+		}
+	}
+
+	award.Heading = rule.heading
+	award.Detail = rule.detail(best)
+	return award, ok
+}
+
+// String returns the award winners and result, like: `42 Joe Bloggs (1m4.401s)`.
+func (a *Award) String() string {
+	return fmt.Sprintf("%s (%s)", strings.Join(a.Winners, ", "), a.Detail)
+}
+
+// secondsToDuration converts seconds to a duration rounded to the same precision as Natsoft lap times.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(lapPrecision)
+}
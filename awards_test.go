@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalcAwards(t *testing.T) {
+	drivers := []Driver{
+		{RaceNumber: "1", Name: "Ann", Fastest: 64400 * time.Millisecond, Qualify: 66 * time.Second, Laps: 12, CV: 1.5, StdDev: 0.9},
+		{RaceNumber: "2", Name: "Bob", Fastest: 64400 * time.Millisecond, Qualify: 65 * time.Second, Laps: 14, CV: 0.8, StdDev: 0.5},
+		{RaceNumber: "3", Name: "Cal", Fastest: 65 * time.Second, Qualify: 68 * time.Second, Laps: 14, CV: 0.8, StdDev: 0.5},
+		{RaceNumber: "4", Name: "Dee", Fastest: 70 * time.Second, Qualify: 69 * time.Second, Laps: 3},
+		{RaceNumber: "5", Name: "Eve"}, // Didn't set a lap time.
+	}
+
+	tests := []struct {
+		name    string
+		winners []string
+		detail  string
+	}{
+		{"fastest", []string{"1 Ann", "2 Bob"}, "1m4.4s"},
+		{"laps", []string{"2 Bob", "3 Cal"}, "14 laps"},
+		{"consistent", []string{"2 Bob", "3 Cal"}, "CV 0.80%"},
+		{"improved", []string{"3 Cal"}, "3s faster than qualifying"},
+	}
+
+	defer func(awards []string) { cfg.Awards = awards }(cfg.Awards)
+	for _, test := range tests {
+		cfg.Awards = []string{test.name}
+		awards := calcAwards(drivers)
+		if len(awards) != 1 {
+			t.Errorf("%s: got %d awards, want 1", test.name, len(awards))
+			continue
+		}
+		if !reflect.DeepEqual(awards[0].Winners, test.winners) || awards[0].Detail != test.detail {
+			t.Errorf("%s: got %v (%s), want %v (%s)", test.name, awards[0].Winners, awards[0].Detail, test.winners, test.detail)
+		}
+	}
+
+	// No award is given when no drivers are eligible.
+	cfg.Awards = []string{"improved", "unknown"}
+	if awards := calcAwards(drivers[3:]); len(awards) != 0 {
+		t.Errorf("got %v, want no awards", awards)
+	}
+}
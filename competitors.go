@@ -0,0 +1,544 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// competitorsPrefix is the start of each competitor list file name. Lists saved before they were stored per event are named `competitors.txt`.
+	competitorsPrefix = "competitors"
+
+	competitorsSyntax = "Please enter racing numbers separated by a space or comma. Ranges like 100-120 are included and numbers prefixed with ! like !47 are excluded."
+)
+
+// Racing numbers, ranges and exclusions in competitor lists, like `47`, `100-120`, `!47` or `!100-120`.
+var reEntryNumbers = regexp.MustCompile(`^(!?)(\d{1,3})(?:-(\d{1,3}))?$`)
+
+// Entry is a competitor entered in the event.
+type Entry struct {
+	RaceNumber string
+	Name       string // Driver's name, optional.
+	Class      string // Car model or class used to look up handicaps, optional. Overrides the `cars` setting.
+	Car        string // Car make and model, optional. Used to look up handicaps when Class is empty.
+
+	// Eligibility to be scored in the series. Competitors are eligible unless listed otherwise.
+	Unpaid        bool // Entry fee not paid.
+	NonMember     bool // Club membership not current.
+	IneligibleCar bool // Car not eligible for the series.
+
+	// Runs driven when the car is shared by several drivers, where zero is the Qualifying session. Empty when the driver drove every run.
+	Runs []uint
+}
+
+// drove returns true if the driver drove during run.
+func (e *Entry) drove(run uint) bool {
+	if len(e.Runs) == 0 {
+		return true
+	}
+
+	for _, r := range e.Runs {
+		if r == run {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runs returns the runs driven in the record syntax read by parseEntryRecord, like `Q 1 3`.
+func (e *Entry) runs() string {
+	runs := make([]string, len(e.Runs))
+	for i, r := range e.Runs {
+		if r == 0 {
+			runs[i] = runQualify
+			continue
+		}
+		runs[i] = strconv.Itoa(int(r))
+	}
+
+	return strings.Join(runs, " ")
+}
+
+// Eligibility statuses in competitor list records.
+const (
+	statusUnpaid        = "unpaid"
+	statusNonMember     = "non-member"
+	statusIneligibleCar = "ineligible-car"
+
+	runQualify = "Q" // The Qualifying session in the runs driven by a shared car driver.
+)
+
+// ineligible returns the reasons why the competitor isn't eligible to be scored, otherwise an empty string.
+func (e *Entry) ineligible() string {
+	var reasons []string
+	if e.Unpaid {
+		reasons = append(reasons, "Entry not paid")
+	}
+	if e.NonMember {
+		reasons = append(reasons, "Membership not current")
+	}
+	if e.IneligibleCar {
+		reasons = append(reasons, "Car not eligible")
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+// status returns the competitor's eligibility in the record syntax read by parseEntryRecord.
+func (e *Entry) status() string {
+	var statuses []string
+	if e.Unpaid {
+		statuses = append(statuses, statusUnpaid)
+	}
+	if e.NonMember {
+		statuses = append(statuses, statusNonMember)
+	}
+	if e.IneligibleCar {
+		statuses = append(statuses, statusIneligibleCar)
+	}
+
+	return strings.Join(statuses, " ")
+}
+
+// String returns the racing number followed by the driver's name, if known.
+func (e *Entry) String() string {
+	return strings.TrimSpace(e.RaceNumber + " " + e.Name)
+}
+
+// parseCompetitors returns the competitors listed in src and a description of each invalid line.
+//
+// Each line contains either:
+//   - racing numbers separated by spaces or commas, like `1 881, 4 55`,
+//   - ranges of racing numbers, like `100-120`,
+//   - exclusions of withdrawn entries, like `!47` or `!100-105`, or
+//   - a single record of the racing number, driver's name, car class, car, eligibility and runs driven separated by commas or tabs, like `47, Jack Black, Spitfire, Triumph Spitfire Mk3, unpaid, Q 1 3`.
+//
+// Anything after a # is a comment. Exclusions apply to the whole list regardless of which line they are on.
+// A car shared by several drivers is listed as a record for each driver with the runs they drove.
+func parseCompetitors(src []byte) (entries []Entry, errs []string) {
+	excluded := map[string]bool{}
+
+	lines := bytes.Split(bytes.TrimSpace(src), lineDelimiter)
+	for i := range lines {
+		line := string(lines[i])
+		if c := strings.Index(line, "#"); c >= 0 {
+			line = line[:c]
+		}
+
+		tokens := strings.FieldsFunc(line, isEntrySeparator)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		if !isEntryRecord(tokens) {
+			for _, token := range tokens {
+				numbers, exclude, err := entryNumbers(token)
+				if err != "" {
+					errs = append(errs, fmt.Sprintf("line %d: %s", i+1, err))
+					continue
+				}
+				for _, raceNumber := range numbers {
+					if exclude {
+						excluded[raceNumber] = true
+					} else if indexOfEntry(entries, raceNumber) < 0 {
+						entries = append(entries, Entry{RaceNumber: raceNumber})
+					}
+				}
+			}
+			continue
+		}
+
+		entry, err := parseEntryRecord(line)
+		if err != "" {
+			errs = append(errs, fmt.Sprintf("line %d: %s", i+1, err))
+			continue
+		}
+		if j := indexOfEntry(entries, entry.RaceNumber); j >= 0 {
+			if len(entry.Runs) >= 1 && len(entries[j].Runs) >= 1 {
+				// Another driver sharing the car.
+				if err = sharedRuns(entries, &entry); err != "" {
+					errs = append(errs, fmt.Sprintf("line %d: %s", i+1, err))
+					continue
+				}
+				entries = append(entries, entry)
+				continue
+			}
+			if len(entries[j].Runs) >= 1 {
+				// Without the runs driven, the driver would be scored using every lap of the shared car.
+				errs = append(errs, fmt.Sprintf("line %d: car %s is shared, so the runs each driver drove are required", i+1, entry.RaceNumber))
+				continue
+			}
+
+			// A record adds details to a racing number listed earlier.
+			entries[j] = entry
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	// Remove withdrawn entries.
+	for i := 0; i < len(entries); i++ {
+		if excluded[entries[i].RaceNumber] {
+			entries = append(entries[:i], entries[i+1:]...)
+			i--
+		}
+	}
+
+	return
+}
+
+func isEntrySeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t'
+}
+
+// isEntryRecord returns true when a racing number is followed by a driver's name, rather than only racing numbers, ranges and exclusions.
+func isEntryRecord(tokens []string) bool {
+	if strings.IndexFunc(tokens[0], isNotDigit) >= 0 {
+		return false
+	}
+
+	for _, token := range tokens[1:] {
+		if strings.IndexFunc(token, isLetter) >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isNotDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// entryNumbers returns the racing numbers in token, like `47`, `100-120` or `!47`, and whether they are excluded.
+func entryNumbers(token string) (numbers []string, exclude bool, err string) {
+	m := reEntryNumbers.FindStringSubmatch(token)
+	if m == nil {
+		return nil, false, fmt.Sprintf("invalid racing number %q", token)
+	}
+
+	exclude = m[1] != ""
+	if m[3] == "" {
+		return []string{m[2]}, exclude, ""
+	}
+
+	low, _ := strconv.Atoi(m[2])  //nolint:errcheck // Matched by reEntryNumbers.
+	high, _ := strconv.Atoi(m[3]) //nolint:errcheck // Matched by reEntryNumbers.
+	if low > high {
+		return nil, exclude, fmt.Sprintf("invalid range %q, the first racing number must be lower", token)
+	}
+
+	// Keep any leading zeros, like 007-009.
+	for n := low; n <= high; n++ {
+		numbers = append(numbers, fmt.Sprintf("%0*d", len(m[2]), n))
+	}
+
+	return numbers, exclude, ""
+}
+
+// parseEntryRecord returns the racing number, driver's name, car class, car and eligibility in line.
+// Eligibility is a space separated list of statusUnpaid, statusNonMember or statusIneligibleCar.
+// Fields are separated by tabs, otherwise commas, otherwise spaces (see spacedRecord).
+func parseEntryRecord(line string) (entry Entry, err string) {
+	var fields []string
+	switch {
+	case strings.Contains(line, "\t"):
+		fields = strings.Split(line, "\t")
+	case strings.Contains(line, ","):
+		fields = strings.Split(line, ",")
+	default:
+		fields = spacedRecord(line)
+	}
+
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	// Ignore trailing empty fields.
+	for len(fields) >= 2 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+
+	const maxFields = 6
+	if len(fields) > maxFields {
+		return entry, fmt.Sprintf("too many fields in %q, expected: number, name, class, car, eligibility, runs", strings.TrimSpace(line))
+	}
+
+	entry.RaceNumber = fields[0]
+	if m := reEntryNumbers.FindStringSubmatch(entry.RaceNumber); m == nil || m[1] != "" || m[3] != "" {
+		return entry, fmt.Sprintf("invalid racing number %q in record %q", entry.RaceNumber, strings.TrimSpace(line))
+	}
+	if len(fields) >= 2 {
+		entry.Name = fields[1]
+	}
+	if len(fields) >= 3 {
+		entry.Class = fields[2]
+	}
+	if len(fields) >= 4 {
+		entry.Car = fields[3]
+	}
+	if len(fields) >= 5 {
+		for _, status := range strings.Fields(fields[4]) {
+			switch strings.ToLower(status) {
+			case statusUnpaid:
+				entry.Unpaid = true
+			case statusNonMember:
+				entry.NonMember = true
+			case statusIneligibleCar:
+				entry.IneligibleCar = true
+			default:
+				return entry, fmt.Sprintf("invalid eligibility %q in record %q, expected: %s, %s or %s", status, strings.TrimSpace(line), statusUnpaid, statusNonMember, statusIneligibleCar)
+			}
+		}
+	}
+	if len(fields) >= maxFields {
+		for _, run := range strings.Fields(fields[5]) {
+			if strings.EqualFold(run, runQualify) {
+				entry.Runs = append(entry.Runs, 0)
+				continue
+			}
+			r, e := strconv.ParseUint(run, 10, 0)
+			if e != nil {
+				return entry, fmt.Sprintf("invalid run %q in record %q, expected %s or a run number like 1", run, strings.TrimSpace(line), runQualify)
+			}
+			entry.Runs = append(entry.Runs, uint(r))
+		}
+		if len(entry.Runs) >= 1 && entry.Name == "" {
+			return entry, fmt.Sprintf("the driver's name is required for a shared car in record %q", strings.TrimSpace(line))
+		}
+	}
+
+	return entry, ""
+}
+
+// spacedRecord returns the fields of a record separated by spaces, like `47 Jack Black Spitfire`.
+// The last word is the car class when it's listed in the `handicaps` setting, otherwise the rest of the line is the driver's name.
+func spacedRecord(line string) (fields []string) {
+	words := strings.Fields(line)
+	if len(words) <= 2 {
+		return words
+	}
+
+	last := len(words) - 1
+	if _, ok := cfg.Handicaps[words[last]]; ok {
+		return []string{words[0], strings.Join(words[1:last], " "), words[last]}
+	}
+
+	return []string{words[0], strings.Join(words[1:], " ")}
+}
+
+// formatCompetitors returns the list of competitors in the syntax read by parseCompetitors.
+// Competitors without any details are listed on one line, followed by one record per line for the rest.
+func formatCompetitors(entries []Entry) string {
+	var numbers, records []string
+	for i := range entries {
+		e := &entries[i]
+		if e.Name == "" && e.Class == "" && e.Car == "" && e.ineligible() == "" {
+			numbers = append(numbers, e.RaceNumber)
+			continue
+		}
+		records = append(records, e.record())
+	}
+
+	if len(numbers) >= 1 {
+		records = append([]string{strings.Join(numbers, " ")}, records...)
+	}
+
+	return strings.Join(records, newLine)
+}
+
+// car returns the car class or model used to look up the competitor's handicap.
+func (e *Entry) car() string {
+	switch {
+	case e.Class != "":
+		return e.Class
+	case e.Car != "":
+		return e.Car
+	}
+
+	return cfg.Cars[e.RaceNumber]
+}
+
+// record returns the competitor's details in the record syntax read by parseEntryRecord.
+func (e *Entry) record() string {
+	fields := []string{e.RaceNumber, e.Name, e.Class, e.Car, e.status(), e.runs()}
+	for len(fields) >= 2 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+
+	// Separate the fields with tabs when any contain a comma.
+	for i := range fields {
+		if strings.Contains(fields[i], ",") {
+			return strings.Join(fields, "\t")
+		}
+	}
+
+	return strings.Join(fields, ", ")
+}
+
+// sharedRuns returns a description of the runs the entry drove that are also declared by another driver sharing the car, otherwise an empty string.
+func sharedRuns(entries []Entry, entry *Entry) string {
+	for i := range entries {
+		if !strings.EqualFold(entries[i].RaceNumber, entry.RaceNumber) {
+			continue
+		}
+		for _, run := range entry.Runs {
+			if entries[i].drove(run) {
+				return fmt.Sprintf("run %s of car %s is also declared for %s", runName(int(run)), entry.RaceNumber, entries[i].Name)
+			}
+		}
+	}
+
+	return ""
+}
+
+// isEligibleCar returns true if any driver entered with raceNumber is eligible to be scored.
+func isEligibleCar(entries []Entry, raceNumber string) bool {
+	for i := range entries {
+		if strings.EqualFold(entries[i].RaceNumber, raceNumber) && entries[i].ineligible() == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexOfEntry returns the index of the competitor with raceNumber, or -1 when not found.
+func indexOfEntry(entries []Entry, raceNumber string) int {
+	for i := range entries {
+		if strings.EqualFold(entries[i].RaceNumber, raceNumber) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// competitorsFileName returns the file name of the event's competitor list, like `competitors-2023-03-12 Sports Car Track Day.txt`.
+func competitorsFileName(meta *Meta) string {
+	return meta.eventFileName(competitorsPrefix)
+}
+
+// getCompetitors returns the list of competitors entered in the event after the user confirms it,
+// or nil when the list should be typed in instead.
+// When the event doesn't have a list, the most recently saved list is offered so it isn't reused without warning.
+func getCompetitors(meta *Meta) []Entry {
+	fileName := competitorsFileName(meta)
+	src, err := ioutil.ReadFile(fileName)
+	isEventList := err == nil && len(bytes.TrimSpace(src)) >= 1
+	if !isEventList {
+		fileName = latestCompetitorsFile()
+		if fileName != "" {
+			src, err = ioutil.ReadFile(fileName)
+			checkErr(err)
+		}
+	}
+
+	entries, errs := parseCompetitors(src)
+	for i := range errs {
+		fmt.Println(fileName, errs[i])
+	}
+	if len(entries) == 0 {
+		fmt.Println(competitorsSyntax)
+		return nil
+	}
+
+	fmt.Printf("Found %d competitors in %s:%s%s%[3]s", len(entries), fileName, newLine, formatCompetitors(entries))
+	if date := listDate(fileName, src); !sameDay(date, meta.day()) {
+		fmt.Printf("WARNING: this list of competitors is dated %s but the event is dated %s.\n", date.Format(dateFormat), meta.day().Format(dateFormat))
+	}
+
+	fmt.Println("Use this list of competitors? [ y / n ]")
+	if !yes(input()) {
+		fmt.Println(competitorsSyntax)
+		return nil
+	}
+
+	if !isEventList {
+		saveCompetitors(meta, entries)
+	}
+
+	return entries
+}
+
+// loadCompetitors returns the competitors in the event's competitor list without prompting, or nil when the event doesn't have a list saved.
+func loadCompetitors(meta *Meta) []Entry {
+	fileName := competitorsFileName(meta)
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		fmt.Printf("No list of competitors saved for the event in %s. Run without -advise to choose the competitors.%s", fileName, newLine)
+		return nil
+	}
+
+	entries, errs := parseCompetitors(src)
+	for i := range errs {
+		fmt.Println(fileName, errs[i])
+	}
+
+	return entries
+}
+
+// saveCompetitors writes the list of competitors to the event's competitor list file, headed by the event name and date.
+func saveCompetitors(meta *Meta, entries []Entry) {
+	fileName := competitorsFileName(meta)
+	src := fmt.Sprintf("# %s %s%s%s%[3]s", meta.Title, meta.day().Format(dateFormat), newLine, formatCompetitors(entries))
+	checkErr(ioutil.WriteFile(fileName, []byte(src), filePermission))
+	fmt.Println("Saved the list of competitors in", fileName)
+}
+
+// latestCompetitorsFile returns the most recently modified competitor list in the working directory, or an empty string when none exist.
+func latestCompetitorsFile() (fileName string) {
+	matches, err := filepath.Glob(competitorsPrefix + "*.txt")
+	checkErr(err)
+
+	var latest time.Time
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+			fileName = match
+		}
+	}
+
+	return
+}
+
+// listDate returns the date in the competitor list's comments, or the date the file was last modified.
+func listDate(fileName string, src []byte) time.Time {
+	for _, line := range bytes.Split(src, lineDelimiter) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("#")) {
+			continue
+		}
+		if date := parseDate(string(reDate.Find(line))); !date.IsZero() {
+			return date
+		}
+	}
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// sameDay returns true if both times are on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	return a.Format(dateFormat) == b.Format(dateFormat)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompetitorsFileName(t *testing.T) {
+	date := time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		meta Meta
+		want string
+	}{
+		{Meta{Title: "Sports Car Track Day", Date: date}, "competitors-2023-03-12 Sports Car Track Day.txt"},
+		{Meta{Title: "Winton 24/7?", Date: date}, "competitors-2023-03-12 Winton 24 7.txt"},
+		{Meta{Date: date}, "competitors-2023-03-12.txt"},
+	}
+
+	for _, test := range tests {
+		if got := competitorsFileName(&test.meta); got != test.want {
+			t.Errorf("competitorsFileName(%q) = %q, want %q", test.meta.Title, got, test.want)
+		}
+	}
+}
+
+func TestListDate(t *testing.T) {
+	date := time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)
+	if got := listDate("", []byte("# Sports Car Track Day 2023-03-12\n1 4 55")); !got.Equal(date) {
+		t.Errorf("listDate() = %s, want the date in the comment %s", got, date)
+	}
+
+	// Lists without a dated comment use the date the file was last modified.
+	fileName := filepath.Join(t.TempDir(), "competitors.txt")
+	checkErr(ioutil.WriteFile(fileName, []byte("1 4 55"), filePermission))
+	modified := time.Date(2022, 11, 6, 9, 30, 0, 0, time.Local)
+	checkErr(os.Chtimes(fileName, modified, modified))
+	if got := listDate(fileName, []byte("1 4 55")); !sameDay(got, modified) {
+		t.Errorf("listDate() = %s, want the date modified %s", got, modified)
+	}
+}
+
+func TestLoadCompetitors(t *testing.T) {
+	wd, err := os.Getwd()
+	checkErr(err)
+	checkErr(os.Chdir(t.TempDir()))
+	defer func() { checkErr(os.Chdir(wd)) }()
+
+	meta := Meta{Title: "Sports Car Track Day", Date: time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)}
+	if got := loadCompetitors(&meta); got != nil {
+		t.Errorf("loadCompetitors() without a saved list = %+v, want nil", got)
+	}
+	if _, err := os.Stat(competitorsFileName(&meta)); !os.IsNotExist(err) {
+		t.Errorf("loadCompetitors() created %s, want no files saved", competitorsFileName(&meta))
+	}
+
+	entries := []Entry{{RaceNumber: "1"}, {RaceNumber: "47", Name: "Jack Black", Class: "Spitfire"}}
+	saveCompetitors(&meta, entries)
+	if got := loadCompetitors(&meta); !reflect.DeepEqual(got, entries) {
+		t.Errorf("loadCompetitors() = %+v, want %+v", got, entries)
+	}
+}
+
+func TestParseCompetitors(t *testing.T) {
+	tests := []struct {
+		src     string
+		numbers []string
+		errs    int
+	}{
+		{"1 881, 4 55", []string{"1", "881", "4", "55"}, 0},
+		{"100-103,5\n!102", []string{"100", "101", "103", "5"}, 0},
+		{"007-010", []string{"007", "008", "009", "010"}, 0},
+		{"8-10 # Sprint class\n# Withdrawn\n!9", []string{"8", "10"}, 0},
+		{"5 5 5", []string{"5"}, 0},
+		{"47, Jack Black, Spitfire\n48 Ann Lee", []string{"47", "48"}, 0},
+		{"47\n47, Jack Black, Spitfire", []string{"47"}, 0},
+		{"x1 4", []string{"4"}, 1},
+		{"10-8", nil, 1},
+		{"1234", nil, 1},
+		{"47, Jack Black, Spitfire, Triumph, unpaid, Q 1, extra", nil, 1},
+		{"47, Jack Black, TR6, , , Q 1\n47, Jill Black, TR6, , , 2", []string{"47", "47"}, 0},
+		{"47\n47, Jack Black, TR6, , , Q 1", []string{"47"}, 0},
+		{"47, Jack Black, TR6, , , Q 1\n47, Jill Black, TR6, , , 1", []string{"47"}, 1},
+		{"47, Jack Black, TR6, , , Q 1\n47, Jill Black", []string{"47"}, 1}, // Sharing the car without listing the runs driven.
+	}
+
+	for _, test := range tests {
+		entries, errs := parseCompetitors([]byte(test.src))
+		var numbers []string
+		for i := range entries {
+			numbers = append(numbers, entries[i].RaceNumber)
+		}
+		if !reflect.DeepEqual(numbers, test.numbers) || len(errs) != test.errs {
+			t.Errorf("parseCompetitors(%q) = %v %q, want %v and %d errors", test.src, numbers, errs, test.numbers, test.errs)
+		}
+	}
+}
+
+func TestParseEntryRecord(t *testing.T) {
+	defer func(handicaps map[string]float64) { cfg.Handicaps = handicaps }(cfg.Handicaps)
+	cfg.Handicaps = map[string]float64{"Spitfire": 1.1, "TR6": 1}
+
+	tests := []struct {
+		line    string
+		want    Entry
+		isError bool
+	}{
+		{line: "47, Jack Black, Spitfire", want: Entry{RaceNumber: "47", Name: "Jack Black", Class: "Spitfire"}},
+		{line: "47\tBlack Jack\tTR6", want: Entry{RaceNumber: "47", Name: "Black Jack", Class: "TR6"}},
+		{line: "47 Jack Black Spitfire", want: Entry{RaceNumber: "47", Name: "Jack Black", Class: "Spitfire"}},
+		{line: "47 Jack Black", want: Entry{RaceNumber: "47", Name: "Jack Black"}},
+		{line: "47 Jack", want: Entry{RaceNumber: "47", Name: "Jack"}},
+		{line: "47 Jack Van Black", want: Entry{RaceNumber: "47", Name: "Jack Van Black"}},
+		{line: "47 Jack Black GT6", want: Entry{RaceNumber: "47", Name: "Jack Black GT6"}}, // GT6 isn't listed in the handicaps.
+		{line: "47, Jack Black, GT6", want: Entry{RaceNumber: "47", Name: "Jack Black", Class: "GT6"}},
+		{line: "47\tBlack, Jack\t\tTriumph Spitfire Mk3\tunpaid non-member", want: Entry{RaceNumber: "47", Name: "Black, Jack", Car: "Triumph Spitfire Mk3", Unpaid: true, NonMember: true}},
+		{line: "47, Jack Black, , , ineligible-car", want: Entry{RaceNumber: "47", Name: "Jack Black", IneligibleCar: true}},
+		{line: "47, Jack Black, TR6, , , Q 1 3", want: Entry{RaceNumber: "47", Name: "Jack Black", Class: "TR6", Runs: []uint{0, 1, 3}}},
+		{line: "47, , TR6, , , 1", isError: true},
+		{line: "47, Jack Black, TR6, , , 1 two", isError: true},
+		{line: "47, Jack Black, Spitfire, , paid", isError: true},
+		{line: "47, Jack Black, Spitfire, Triumph, unpaid, extra", isError: true},
+		{line: "!47, Jack Black", isError: true},
+		{line: "40-47, Jack Black", isError: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseEntryRecord(test.line)
+		if (err != "") != test.isError {
+			t.Errorf("parseEntryRecord(%q) error = %q, want error %v", test.line, err, test.isError)
+			continue
+		}
+		if err == "" && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseEntryRecord(%q) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestFormatCompetitors(t *testing.T) {
+	entries := []Entry{{RaceNumber: "1"}, {RaceNumber: "47", Name: "Jack Black", Class: "Spitfire"}, {RaceNumber: "4"}, {RaceNumber: "48", Name: "Ann Lee"}}
+	got, errs := parseCompetitors([]byte(formatCompetitors(entries)))
+	if len(errs) != 0 || len(got) != len(entries) {
+		t.Fatalf("parseCompetitors(formatCompetitors()) = %+v %q, want %+v", got, errs, entries)
+	}
+	for i := range entries {
+		if j := indexOfEntry(got, entries[i].RaceNumber); j < 0 || !reflect.DeepEqual(got[j], entries[i]) {
+			t.Errorf("entry %s wasn't read back the same, got %+v", entries[i].RaceNumber, got)
+		}
+	}
+}
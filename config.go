@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const configFile = "config.json"
+
+// Config contains the event settings that can be changed without rebuilding the program.
+// Settings are read from configFile in the working directory (if present) and can be overridden by command line flags.
+type Config struct {
+	MinLaps        uint   `json:"minLaps"`        // Minimum quantity of laps completed (excluding Qualifying) to be classified.
+	MinRuns        uint   `json:"minRuns"`        // Minimum quantity of runs completed (excluding Qualifying) to be classified.
+	RequireQualify bool   `json:"requireQualify"` // A Qualifying lap time is required to be classified.
+	Ranking        string `json:"ranking"`        // Ranking scheme for drivers with identical results: rankStandard, rankDense or rankOrdinal.
+
+	Cars      map[string]string  `json:"cars"`      // Car model or class driven by each racing number, used to look up Handicaps.
+	Handicaps map[string]float64 `json:"handicaps"` // Handicap multiplier applied to the Percentage score for each car model or class.
+
+	PerRun bool `json:"perRun"` // Display the statistics of each run in HTML and text output.
+
+	Consistency bool     `json:"consistency"` // Display the mean, median, standard deviation and coefficient of variation columns.
+	Awards      []string `json:"awards"`      // Names of the awards to display, in order. See awardRules.
+
+	Analysis    bool `json:"analysis"`    // Display the gap analysis section.
+	Progression bool `json:"progression"` // Display the standings after each run in HTML and spreadsheet output.
+
+	// Event details, overriding those found in the Natsoft results.
+	Event   string `json:"event"`
+	Circuit string `json:"circuit"`
+	Club    string `json:"club"`
+	Date    string `json:"date"` // Formatted as dateFormat.
+
+	EntryColumns EntryColumns `json:"entryColumns"` // Column headings of the online entry system's CSV export.
+
+	LapColumns LapColumns `json:"lapColumns"` // Column headings of lap time spreadsheets.
+
+	Transponders map[string]string `json:"transponders"` // Racing number of each transponder, used to import passings.
+	RunGap       float64           `json:"runGap"`       // Minutes without any passings that start a new run.
+	Schedule     []string          `json:"schedule"`     // Time of day each run started, starting with Qualifying, used to align data logger laps.
+
+	GlitchTolerance float64 `json:"glitchTolerance"` // How close a lap must be to double or half the median lap time to be detected as a transponder glitch, like 0.2 for ±20%.
+}
+
+// cfg contains the default settings used when configFile and command line flags are absent.
+var cfg = Config{
+	MinLaps:         1,
+	MinRuns:         1,
+	RequireQualify:  true,
+	Ranking:         rankStandard,
+	GlitchTolerance: 0.2,
+	RunGap:          10,
+	EntryColumns: EntryColumns{
+		RaceNumber: "Race Number",
+		Driver:     "Driver",
+		Car:        "Car",
+		Class:      "Class",
+		Paid:       "Paid",
+		Member:     "Membership Current",
+		Eligible:   "Car Eligible",
+	},
+	LapColumns: LapColumns{
+		RaceNumber: "Race Number",
+		Driver:     "Driver",
+		Run:        "Run",
+		Lap:        "Lap",
+		LapTime:    "Lap Time",
+		TimeFormat: timeAuto,
+	},
+}
+
+// loadConfig reads configFile and registers the command line flags that override it.
+// Must be called before flag.Parse().
+func loadConfig() {
+	if src, err := ioutil.ReadFile(configFile); err == nil {
+		if err = json.Unmarshal(src, &cfg); err != nil {
+			fmt.Println("Unable to read", configFile, err)
+		} else {
+			fmt.Println("Using the settings in", configFile)
+		}
+	}
+	if err := checkRanking(cfg.Ranking); err != nil {
+		fmt.Println(configFile, err)
+		os.Exit(2)
+	}
+
+	flag.UintVar(&cfg.MinLaps, "minlaps", cfg.MinLaps, "Minimum quantity of laps a driver must complete to be classified.")
+	flag.UintVar(&cfg.MinRuns, "minruns", cfg.MinRuns, "Minimum quantity of runs a driver must complete to be classified.")
+	flag.BoolVar(&cfg.RequireQualify, "qualify", cfg.RequireQualify, "Drivers must set a qualifying lap time to be classified.")
+	flag.BoolVar(&cfg.PerRun, "runs", cfg.PerRun, "Display the fastest, slowest and average lap times of each run.")
+	flag.BoolVar(&cfg.Consistency, "consistency", cfg.Consistency, "Display the mean, median, standard deviation and coefficient of variation of lap times.")
+	flag.Func("awards", "Comma separated list of awards to display: "+awardNames()+".", func(s string) error {
+		cfg.Awards = strings.Split(s, ",")
+		return nil
+	})
+	flag.BoolVar(&cfg.Analysis, "analysis", cfg.Analysis, "Display each driver's gap to the driver ahead and the lap times needed to gain a position.")
+	flag.BoolVar(&cfg.Progression, "progression", cfg.Progression, "Display each driver's position after each run and their position change during the last run.")
+	flag.Float64Var(&cfg.GlitchTolerance, "glitch", cfg.GlitchTolerance, "Tolerance used to detect laps about double or half the driver's median lap time, like 0.2 for ±20%.")
+	flag.Float64Var(&cfg.RunGap, "rungap", cfg.RunGap, "Minutes without any passings that start a new run, when importing passings.")
+	flag.Func("schedule", "Comma separated list of the time of day each run started, starting with Qualifying, like 09:00,10:30,13:00.", func(s string) error {
+		cfg.Schedule = strings.Split(s, ",")
+		return nil
+	})
+	flag.StringVar(&cfg.Event, "event", cfg.Event, "Event name, instead of the first line of the Natsoft results.")
+	flag.StringVar(&cfg.Circuit, "circuit", cfg.Circuit, "Circuit name, instead of the circuit found in the Natsoft results.")
+	flag.StringVar(&cfg.Club, "club", cfg.Club, "Organising club, instead of the club found in the Natsoft results.")
+	flag.StringVar(&cfg.Date, "date", cfg.Date, "Event date formatted as "+dateFormat+", instead of the date found in the Natsoft results.")
+	flag.Func("ranking", fmt.Sprintf("Ranking scheme for drivers with identical results: %s, %s or %s. (default %q)", rankStandard, rankDense, rankOrdinal, cfg.Ranking), func(s string) error {
+		cfg.Ranking = s
+		return checkRanking(s)
+	})
+}
+
+// checkRanking returns an error listing the ranking schemes allowed when ranking is unknown.
+func checkRanking(ranking string) error {
+	switch ranking {
+	case rankStandard, rankDense, rankOrdinal:
+		return nil
+	}
+
+	return fmt.Errorf("unknown ranking scheme %q, expected %s, %s or %s", ranking, rankStandard, rankDense, rankOrdinal)
+}
+
+// hasHandicaps returns true when handicapped scores are calculated and displayed.
+func (c *Config) hasHandicaps() bool {
+	return len(c.Handicaps) >= 1
+}
+
+// handicap returns the handicap multiplier for the car model or class, defaulting to 1 when not listed.
+func (c *Config) handicap(car string) float64 {
+	if factor, ok := c.Handicaps[car]; ok {
+		return factor
+	}
+
+	return 1
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+)
+
+// csvLaps returns every lap parsed in comma separated values format.
+func csvLaps(drivers []Driver) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.UseCRLF = newLine == "\r\n"
+
+	checkErr(w.Write([]string{hRacingNumber, hDriver, hLap, hRun, hRunLap, hSeconds, hStatus, hNote, hSource}))
+
+	for i := range drivers {
+		for l := range drivers[i].Timing {
+			lap := &drivers[i].Timing[l]
+
+			var seconds string
+			if lap.Status != lapExcluded {
+				seconds = strconv.FormatFloat(lap.Time.Seconds(), 'f', decimalPlaces, 64)
+			}
+
+			checkErr(w.Write([]string{
+				drivers[i].RaceNumber,
+				drivers[i].Name,
+				strconv.FormatUint(uint64(lap.Natsoft), 10),
+				strconv.FormatUint(uint64(lap.Run), 10),
+				strconv.FormatUint(uint64(lap.Number), 10),
+				seconds,
+				lap.Status,
+				lap.Note,
+				lap.Source,
+			}))
+		}
+	}
+
+	w.Flush()
+	checkErr(w.Error())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVLaps(t *testing.T) {
+	driver := Driver{RaceNumber: "42", Name: "Joe Bloggs", Timing: parseLaps([]byte(" 42 Joe Bloggs  1:10.1234 -:--.---- 1:20.0000 1:05.4321 "))}
+
+	want := strings.Join([]string{
+		hRacingNumber + "," + hDriver + "," + hLap + "," + hRun + "," + hRunLap + "," + hSeconds + "," + hStatus + "," + hNote + "," + hSource,
+		"42,Joe Bloggs,1,0,1,70.1234," + lapQualifying + ",,",
+		"42,Joe Bloggs,2,0,2,," + lapExcluded + ",,",
+		"42,Joe Bloggs,3,1,1,80.0000," + lapFormation + ",,",
+		"42,Joe Bloggs,4,1,2,65.4321," + lapCounted + ",,",
+	}, newLine) + newLine
+	if got := string(csvLaps([]Driver{driver})); got != want {
+		t.Errorf("csvLaps() =\n%s\nwant\n%s", got, want)
+	}
+}
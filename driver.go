@@ -7,8 +7,11 @@ import (
 	"math"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/speedyhoon/utl"
 )
 
 const (
@@ -21,13 +24,15 @@ const (
 	Select all of the individual lap times by pressing Ctrl + A
 	Then copy by pressing Ctrl + C
 	Run the TriumphChallenge program
-	Type in all the competitors racing numbers that are in the event, each separated by a space.
+	Confirm the list of competitors found for the event, or type in all the competitors racing numbers that are in the event, each separated by a space.
 	Press Enter
 	Results will be generated in the same folder with today's date in spreadsheet, HTML and text format.`
 
 	// Regular expressions.
 	rDriverName   = `([a-zA-Z_\.\-'/]+ )+` // Driver names can contain underscores, periods, hyphens, apostrophes and backslashes.
 	rRacingNumber = `\d{1,3}`
+
+	lapPrecision = 100 * time.Microsecond // Natsoft lap times are accurate to 4 decimal places.
 )
 
 var (
@@ -37,6 +42,7 @@ var (
 	// Matches a list of lap times by a driver.
 	reHasDrivers = regexp.MustCompile(fmt.Sprintf(`\n *%s( %s)+ +((\s*\d{1,2}0 )*(%s[ p])*)*`, rRacingNumber, rDriverName, rLapTimes))
 	reLapTime    = regexp.MustCompile(rLapTimes)
+	reLapGrid    = regexp.MustCompile(fmt.Sprintf(`\s(\d{1,2}0) |%s`, rLapTimes)) // Lap times OR lap counter markers (10, 20, 30...) in the Natsoft lap grid.
 	reNonLaps    = regexp.MustCompile(rNonLaps)
 	reRacingNum  = regexp.MustCompile(fmt.Sprintf("^%s ", rRacingNumber))
 	reDriverName = regexp.MustCompile(rDriverName)
@@ -53,38 +59,137 @@ type Driver struct {
 	Qualify    time.Duration // The fastest time during Qualifying session (aka Practice Run).
 	SlowAv     float64       // Slow Average.
 	Percentage float64       //
-	Runs       uint          // Also known as a `Session`. Zero based index, but the first run is ignored for Qualifying.
+	Car        string        // Car model or class.
+	Handicap   float64       // Handicap multiplier for the car model or class.
+	Score      float64       // Percentage multiplied by the Handicap, used to rank drivers.
+	Mean       float64       // Mean lap time in seconds, excluding Qualifying.
+	Median     float64       // Median lap time in seconds, excluding Qualifying.
+	StdDev     float64       // Sample standard deviation of lap times in seconds, excluding Qualifying.
+	CV         float64       // Coefficient of variation, the StdDev as a percentage of the Mean.
+	Runs       uint          // Also known as a `Session`. Quantity of runs driven, excluding Qualifying.
 	Laps       uint          // Quantity of laps completed excluding Qualifying session.
 	Position   uint          // Only assigned once Driver's slice has been sorted.
+	IsEqual    bool          // Another driver shares the same Position.
+	Reason     string        // Why the driver is Not Classified, empty when classified.
+	RunStats   []Run         // Statistics for each run, where index zero is the Qualifying session.
+	Timing     []Lap         // Every lap in the order completed.
+	Progress   []Standing    // Position after each run, excluding Qualifying.
+	Sources    string        // Where any laps counted came from besides the timing system, like sourceManual.
+}
+
+// Event contains the collated results of an event.
+type Event struct {
+	Name           string
+	Meta           Meta
+	Drivers        []Driver     // Classified drivers sorted by position.
+	NotClassified  []Driver     // Drivers who didn't meet the classification rules.
+	Ineligible     []Driver     // Drivers who ran but aren't eligible to be scored, like an unpaid entry.
+	Missing        []string     // Entered racing numbers without any results, with any suggested matches.
+	Absent         []string     // Ineligible entries without any results, with the reason they are ineligible.
+	Suggestions    []Suggestion // Drivers who weren't entered that might be a missing competitor.
+	LongestNameLen uint         // Used to align the driver names column in text file output.
+	LongestCarLen  uint         // Used to align the car column in text file output.
+	Diagnostics    []Diagnostic
+}
+
+// hasSources returns true if any driver has laps counted from a source other than the timing system, like hand timed laps.
+func (event *Event) hasSources() bool {
+	for _, drivers := range [][]Driver{event.Drivers, event.NotClassified, event.Ineligible} {
+		for i := range drivers {
+			if drivers[i].Sources != "" {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
-// sortResults returns a list of entered drivers, the event name, a list of drivers who are missing results and the longest driver name
-// given the Natsoft results and a list of competitors entered in the event.
-func sortResults(results []byte, enteredCars [][]byte) (drivers []Driver, eventName string, missing []string, longestNameLen uint) {
-	eventName = eventTitle(results)
+// sortResults returns the event results given the laps of every car in the timing results, the event details, a list of competitors entered in the event, lap adjustments and hand timed laps.
+// Transponder glitches detected are appended to adjustments.
+func sortResults(timing []Driver, meta Meta, entries []Entry, adjustments *[]Adjustment, manual []ManualRun) (event Event) {
+	event.Meta = meta
+	event.Name = fmt.Sprintf("%s - %s", championship, event.Meta.Title)
 
-	matches := reHasDrivers.FindAll(results, -1)
+	var others []Driver  // Drivers who weren't entered in the event.
+	var entered []Driver // Entered cars.
+	var cars []Driver    // Eligible cars, before their laps are shared between drivers.
 
 	// Iterate through all competitors lap times.
-	for i := range matches {
-		// If this driver is a competitor.
-		if driver, ok := newDriver(matches[i], enteredCars); ok {
-			drivers = append(drivers, driver)
+	for _, car := range timing {
+		// Ignore any car NOT in the list of competitors entered for the event. Eligibility is checked below.
+		i := indexOfEntry(entries, car.RaceNumber)
+		if i < 0 {
+			others = append(others, car)
+			continue
+		}
 
+		// Copy the laps so adjustments don't change the timing results.
+		car.Timing = append([]Lap(nil), car.Timing...)
+		car.Car = entries[i].car()
+		if car.Name == "" {
+			// Passings don't include the driver's name.
+			car.Name = entries[i].Name
+		}
+		car.score()
+		entered = append(entered, car)
+	}
+
+	for _, car := range manualCars(entered, entries, manual) {
+		event.Diagnostics = append(event.Diagnostics, car.mergeManual(manual)...)
+
+		// Transponder glitches are corrected for the whole car, before its laps are shared between drivers.
+		if isEligibleCar(entries, car.RaceNumber) {
+			event.Diagnostics = append(event.Diagnostics, car.adjust(adjustments)...)
+			cars = append(cars, car)
+		}
+
+		for _, driver := range car.share(entries) {
 			// Work out driver names table column length used in text file output.
-			if l := uint(len(driver.Name)); l > longestNameLen {
-				longestNameLen = l
+			if l := uint(len(driver.Name)); l > event.LongestNameLen {
+				event.LongestNameLen = l
+			}
+			if l := uint(len(driver.Car)); l > event.LongestCarLen {
+				event.LongestCarLen = l
+			}
+
+			if driver.Reason != "" {
+				event.Ineligible = append(event.Ineligible, driver)
+				continue
+			}
+
+			if driver.Reason = driver.classify(); driver.Reason != "" {
+				event.NotClassified = append(event.NotClassified, driver)
+				continue
 			}
+
+			event.Drivers = append(event.Drivers, driver)
 		}
 	}
 
-	sortDrivers(drivers)
+	event.Diagnostics = append(event.Diagnostics, validateSessions(cars)...)
+
+	sortDrivers(event.Drivers)
+	sortDrivers(event.NotClassified)
+	sortDrivers(event.Ineligible)
+	rankDrivers(event.Drivers)
+
+	if cfg.Progression {
+		progression(&event)
+	}
 
 	// Find if there are any missing competitors.
-	if len(drivers) != len(enteredCars) {
-		for i := range enteredCars {
-			if !hasRacingNum(drivers, enteredCars[i]) {
-				missing = append(missing, string(enteredCars[i]))
+	if len(event.Drivers)+len(event.NotClassified)+len(event.Ineligible) != len(entries) {
+		for i := range entries {
+			reason := entries[i].ineligible()
+			switch {
+			case reason != "" && !hasRacingNum(event.Ineligible, entries[i].RaceNumber):
+				// Ineligible entries without results aren't missing, but are still listed so officials can follow up.
+				event.Absent = append(event.Absent, fmt.Sprintf("%s - %s", entries[i].String(), reason))
+			case reason == "" && !hasRacingNum(event.Drivers, entries[i].RaceNumber) && !hasRacingNum(event.NotClassified, entries[i].RaceNumber):
+				suggestions := suggestMatches(&entries[i], others)
+				event.Suggestions = append(event.Suggestions, suggestions...)
+				event.Missing = append(event.Missing, missing(&entries[i], suggestions))
 			}
 		}
 	}
@@ -92,13 +197,15 @@ func sortResults(results []byte, enteredCars [][]byte) (drivers []Driver, eventN
 	return
 }
 
-func eventTitle(results []byte) string {
-	lines := bytes.Split(results, lineDelimiter)
-	for i := range lines {
-		lines[i] = bytes.TrimSpace(lines[i])
-		if len(lines[i]) >= 1 {
-			return fmt.Sprintf("%s - %s", championship, string(lines[i]))
-		}
+// classify returns the reason why the driver doesn't meet the classification rules, otherwise an empty string.
+func (driver *Driver) classify() string {
+	switch {
+	case cfg.RequireQualify && driver.Qualify == 0:
+		return "No qualifying lap time"
+	case driver.Runs < cfg.MinRuns:
+		return fmt.Sprintf("Completed %d of %d runs required", driver.Runs, cfg.MinRuns)
+	case driver.Laps < cfg.MinLaps:
+		return fmt.Sprintf("Completed %d of %d laps required", driver.Laps, cfg.MinLaps)
 	}
 
 	return ""
@@ -126,8 +233,8 @@ func sortDrivers(drivers []Driver) {
 
 		// If both drivers have completed the same number of laps.
 		if drivers[i].Runs == drivers[j].Runs {
-			// Sort Percentage in descending order (highest percentage first).
-			return drivers[i].Percentage > drivers[j].Percentage
+			// Sort the handicapped Percentage in descending order (highest percentage first).
+			return drivers[i].Score > drivers[j].Score
 		}
 
 		// Sort by the quantity of runs/session completed in descending order (most runs/sessions first).
@@ -135,31 +242,108 @@ func sortDrivers(drivers []Driver) {
 	})
 }
 
-func newDriver(line []byte, competitors [][]byte) (driver Driver, ok bool) {
-	line = bytes.TrimSpace(line)
-	raceNum := bytes.TrimSpace(reRacingNum.Find(line))
+// Ranking schemes used to assign positions to drivers with identical results.
+const (
+	rankStandard = "standard" // Standard competition ranking, like: 1st, =2nd, =2nd, 4th.
+	rankDense    = "dense"    // Dense ranking, like: 1st, =2nd, =2nd, 3rd.
+	rankOrdinal  = "ordinal"  // Ordinal ranking without ties, like: 1st, 2nd, 3rd, 4th.
+)
+
+// rankDrivers assigns each driver's Position, using the ranking scheme in cfg.Ranking, given drivers are already sorted.
+func rankDrivers(drivers []Driver) {
+	var position uint
+	for i := range drivers {
+		if cfg.Ranking != rankOrdinal && i >= 1 && drivers[i].sameResult(&drivers[i-1]) {
+			drivers[i].Position = drivers[i-1].Position
+			drivers[i].IsEqual = true
+			drivers[i-1].IsEqual = true
+			continue
+		}
 
-	// Ignore any line/entry NOT in the list of paid competitors entered for the event.
-	if !has(competitors, raceNum) {
-		return
+		if cfg.Ranking == rankDense {
+			position++
+		} else {
+			position = uint(i) + 1
+		}
+		drivers[i].Position = position
+	}
+}
+
+// sameResult returns true if both drivers have identical scores.
+func (driver *Driver) sameResult(d *Driver) bool {
+	return driver.Score == d.Score && driver.Runs == d.Runs && driver.Laps == d.Laps
+}
+
+// Ordinal returns the driver's position, like: 1st, =2nd.
+func (driver *Driver) Ordinal() string {
+	return utl.Ordinal(driver.Position, driver.IsEqual)
+}
+
+// parseNatsoft returns the racing number, name and laps of every driver in the Natsoft results.
+func parseNatsoft(results []byte) (drivers []Driver) {
+	for _, line := range reHasDrivers.FindAll(results, -1) {
+		line = bytes.TrimSpace(line)
+		drivers = append(drivers, Driver{
+			RaceNumber: string(bytes.TrimSpace(reRacingNum.Find(line))),
+			Name:       string(bytes.TrimSpace(reDriverName.Find(line))),
+			Timing:     parseLaps(line),
+		})
+	}
+
+	return drivers
+}
+
+// share returns the results of each driver sharing the car, scored using only the runs they drove,
+// or the car's results when it isn't shared. Reason is set for drivers who aren't eligible to be scored.
+func (driver *Driver) share(entries []Entry) (drivers []Driver) {
+	for i := range entries {
+		e := &entries[i]
+		if !strings.EqualFold(e.RaceNumber, driver.RaceNumber) {
+			continue
+		}
+
+		d := *driver
+		if len(e.Runs) >= 1 {
+			d.Name = e.Name
+			d.Car = e.car()
+			d.Timing = nil
+			for _, lap := range driver.Timing {
+				if e.drove(lap.Run) {
+					d.Timing = append(d.Timing, lap)
+				}
+			}
+			d.score()
+		}
+
+		d.Reason = e.ineligible()
+		drivers = append(drivers, d)
 	}
 
-	driver = Driver{
-		RaceNumber: string(raceNum),
-		Name:       string(bytes.TrimSpace(reDriverName.Find(line))),
+	return drivers
+}
+
+// score calculates the driver's results from the laps in Timing.
+func (driver *Driver) score() {
+	// Clear any previously calculated results.
+	*driver = Driver{
+		RaceNumber: driver.RaceNumber,
+		Name:       driver.Name,
+		Car:        driver.Car,
+		Timing:     driver.Timing,
 		Fastest:    math.MaxInt64, // Default the Fastest Lap and Qualifying Lap to the slowest possible time.
 		Qualify:    math.MaxInt64,
 	}
 
-	driver.lapTimes(line)
+	driver.lapTimes()
+	driver.consistency()
 
 	// If at least one session is completed,.
 	//nolint:gomnd // Ignore hardcoded numbers
 	if driver.Runs >= 1 && driver.Qualify != math.MaxInt64 && driver.Fastest != math.MaxInt64 {
-		// Calculate scoring percentage formula.
-		driver.SlowAv = (driver.Slowest.Seconds() + driver.Qualify.Seconds()) / 2
-		driver.Percentage = driver.Fastest.Seconds() / driver.SlowAv * 100
+		driver.SlowAv, driver.Percentage = percentage(driver.Fastest, driver.Slowest, driver.Qualify)
 	}
+	driver.Handicap = cfg.handicap(driver.Car)
+	driver.Score = driver.Percentage * driver.Handicap
 
 	// If Qualifying or Fastest lap times haven't been calculated, clear their values to prevent displaying erroneous results.
 	if driver.Qualify == math.MaxInt64 {
@@ -168,62 +352,159 @@ func newDriver(line []byte, competitors [][]byte) (driver Driver, ok bool) {
 	if driver.Fastest == math.MaxInt64 {
 		driver.Fastest = 0
 	}
+}
 
-	return driver, true
+// percentage calculates the scoring percentage formula: Fastest ÷ ((Slowest + Qualify) ÷ 2) × 100.
+func percentage(fastest, slowest, qualify time.Duration) (slowAv, pct float64) {
+	slowAv = (slowest.Seconds() + qualify.Seconds()) / 2
+	//nolint:gomnd // Ignore hardcoded numbers
+	return slowAv, fastest.Seconds() / slowAv * 100
 }
 
-// lapTimes calculates the slowest, fastest and qualifying lap times, and the quantity of runs and laps completed.
-func (driver *Driver) lapTimes(line []byte) {
+// parseLaps returns every lap listed in a Natsoft driver line, including missing laps.
+func parseLaps(line []byte) (laps []Lap) {
+	var run, number uint
 	var skipNextLap bool
 
-	lapTimes := reLapTime.FindAll(line, -1)
+	lapTimes, natsoft := lapGrid(line)
 
 	// Loop through all lap times.
 	for n := range lapTimes {
+		number++
+		lap := Lap{Run: run, Number: number, Natsoft: natsoft[n]}
+
 		// If the lap is missing a time.
 		if reNonLaps.Match(lapTimes[n]) {
+			lap.Status = lapExcluded
+			laps = append(laps, lap)
+
 			// ... AND If there's another lap in the list, AND the next lap is NOT the end of the Run/Session.
 			if n+1 < len(lapTimes) && !reNonLaps.Match(lapTimes[n+1]) {
-				driver.Runs++
+				run++
+				number = 0
 			}
 
 			skipNextLap = true
 			continue
 		}
 
-		// Skip the first lap of each run, allowing for a grid formation lap. This may change depending on which circuit the race is held at or if formation laps are organized.
-		if skipNextLap {
-			skipNextLap = false
+		var err error
+		lap.Time, err = parseLapTime(string(lapTimes[n]))
+
+		switch {
+		case err != nil:
+			log.Println(err)
+			lap.Status = lapExcluded
+		case skipNextLap:
+			// Skip the first lap of each run, allowing for a grid formation lap. This may change depending on which circuit the race is held at or if formation laps are organized.
+			lap.Status = lapFormation
+		case run == 0:
+			lap.Status = lapQualifying
+		default:
+			lap.Status = lapCounted
+		}
+
+		skipNextLap = false
+		laps = append(laps, lap)
+	}
+
+	return laps
+}
+
+// lapGrid returns the lap times listed in a Natsoft driver line and their lap numbers shown on the Natsoft page.
+// Natsoft lists a lap counter (10, 20, 30...) after every ten laps, counting the laps before it, which realigns lap numbers if any lap times were not copied.
+func lapGrid(line []byte) (lapTimes [][]byte, natsoft []uint) {
+	// Ignore the racing number and driver name, which could be mistaken for a lap counter.
+	start := reLapTime.FindIndex(line)
+	if start == nil {
+		return nil, nil
+	}
+
+	var number uint
+	for _, m := range reLapGrid.FindAllSubmatch(line[start[0]:], -1) {
+		if len(m[1]) >= 1 {
+			counter, err := strconv.ParseUint(string(m[1]), 10, 0)
+			if err == nil {
+				number = uint(counter)
+			}
 			continue
 		}
 
-		lapTime, err := time.ParseDuration(
-			// Convert time format 00:00.0000 to 00m00.0000s so it can be parsed.
-			strings.ReplaceAll(string(lapTimes[n]), ":", "m") + "s",
-		)
-		if err != nil {
-			log.Println(err)
+		number++
+		lapTimes = append(lapTimes, m[0])
+		natsoft = append(natsoft, number)
+	}
+
+	return lapTimes, natsoft
+}
+
+// lapTimes calculates the slowest, fastest and qualifying lap times, and the quantity of runs and laps completed.
+func (driver *Driver) lapTimes() {
+	var lastRun uint
+	for i := range driver.Timing {
+		lap := &driver.Timing[i]
+		// Count each run driven, excluding Qualifying. Drivers sharing a car may not drive every run.
+		if lap.Run > lastRun {
+			driver.Runs++
+			lastRun = lap.Run
+		}
+
+		if !lap.isCounted() {
 			continue
 		}
 
+		// Add an entry for each run until the current run is reached.
+		for uint(len(driver.RunStats)) <= lap.Run {
+			driver.RunStats = append(driver.RunStats, Run{})
+		}
+		driver.RunStats[lap.Run].add(lap.Time)
+		if lap.Source != "" {
+			driver.RunStats[lap.Run].Source = lap.Source
+			if !strings.Contains(driver.Sources, lap.Source) {
+				driver.Sources = strings.TrimPrefix(driver.Sources+", "+lap.Source, ", ")
+			}
+		}
+
 		// Calculate the fastest lap.
-		if lapTime.Seconds() < driver.Fastest.Seconds() {
-			driver.Fastest = lapTime
+		if lap.Time.Seconds() < driver.Fastest.Seconds() {
+			driver.Fastest = lap.Time
 		}
 
-		if driver.Runs >= 1 {
+		if lap.Run >= 1 {
 			// Qualifying laps completed don't count towards the quantity of laps completed during the day.
 			driver.Laps++
 
 			// Only calculate the slowest lap when not in Practice/Qualifying.
-			if lapTime.Seconds() > driver.Slowest.Seconds() {
-				driver.Slowest = lapTime
+			if lap.Time.Seconds() > driver.Slowest.Seconds() {
+				driver.Slowest = lap.Time
 			}
-		} else if lapTime.Seconds() < driver.Qualify.Seconds() {
+		} else if lap.Time.Seconds() < driver.Qualify.Seconds() {
 			// Calculate the fastest qualifying lap only during the qualifying session/run.
-			driver.Qualify = lapTime
+			driver.Qualify = lap.Time
+		}
+	}
+}
+
+// drove returns true if the driver completed a lap during run. Drivers sharing a car may not drive every run.
+func (driver *Driver) drove(run uint) bool {
+	for i := range driver.Timing {
+		if driver.Timing[i].Run == run {
+			return true
 		}
 	}
+
+	return false
+}
+
+// timed returns true if the driver has a lap time during run, from the timing system or hand timed.
+func (driver *Driver) timed(run uint) bool {
+	for i := range driver.Timing {
+		if driver.Timing[i].Run == run && driver.Timing[i].Time > 0 {
+			return true
+		}
+	}
+
+	return false
 }
 
 /*func retrieveBody(path string) (src []byte) {
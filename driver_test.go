@@ -0,0 +1,288 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.RequireQualify, cfg.MinRuns, cfg.MinLaps = true, 2, 6
+
+	tests := []struct {
+		driver Driver
+		reason string
+	}{
+		{Driver{Qualify: 65e9, Runs: 3, Laps: 12}, ""},
+		{Driver{Qualify: 65e9, Runs: 2, Laps: 6}, ""},
+		{Driver{Runs: 3, Laps: 12}, "No qualifying lap time"},
+		{Driver{Qualify: 65e9, Runs: 1, Laps: 8}, "Completed 1 of 2 runs required"},
+		{Driver{Qualify: 65e9, Runs: 2, Laps: 5}, "Completed 5 of 6 laps required"},
+		{Driver{Runs: 1, Laps: 2}, "No qualifying lap time"},
+	}
+
+	for i, test := range tests {
+		if got := test.driver.classify(); got != test.reason {
+			t.Errorf("%d: classify() = %q, want %q", i, got, test.reason)
+		}
+	}
+
+	cfg.RequireQualify = false
+	if got := (&Driver{Runs: 2, Laps: 6}).classify(); got != "" {
+		t.Errorf("classify() without requiring Qualifying = %q, want classified", got)
+	}
+}
+
+func TestRankDrivers(t *testing.T) {
+	// Drivers already sorted, where the second and third drivers have identical results.
+	drivers := []Driver{
+		{RaceNumber: "1", Score: 101.5, Runs: 3, Laps: 12},
+		{RaceNumber: "2", Score: 99.2, Runs: 3, Laps: 12},
+		{RaceNumber: "3", Score: 99.2, Runs: 3, Laps: 12},
+		{RaceNumber: "4", Score: 99.2, Runs: 3, Laps: 11},
+		{RaceNumber: "5", Score: 97.8, Runs: 2, Laps: 8},
+	}
+
+	tests := []struct {
+		ranking  string
+		ordinals []string
+	}{
+		{rankStandard, []string{"1st", "=2nd", "=2nd", "4th", "5th"}},
+		{rankDense, []string{"1st", "=2nd", "=2nd", "3rd", "4th"}},
+		{rankOrdinal, []string{"1st", "2nd", "3rd", "4th", "5th"}},
+	}
+
+	defer func(ranking string) { cfg.Ranking = ranking }(cfg.Ranking)
+	for _, test := range tests {
+		cfg.Ranking = test.ranking
+		d := append([]Driver(nil), drivers...)
+		rankDrivers(d)
+
+		var ordinals []string
+		for i := range d {
+			ordinals = append(ordinals, d[i].Ordinal())
+		}
+		if !reflect.DeepEqual(ordinals, test.ordinals) {
+			t.Errorf("%s ranking: got %v, want %v", test.ranking, ordinals, test.ordinals)
+		}
+	}
+}
+
+func TestCheckRanking(t *testing.T) {
+	for _, ranking := range []string{rankStandard, rankDense, rankOrdinal} {
+		if err := checkRanking(ranking); err != nil {
+			t.Errorf("checkRanking(%q) = %v, want nil", ranking, err)
+		}
+	}
+	for _, ranking := range []string{"", "Standard", "olympic"} {
+		if err := checkRanking(ranking); err == nil {
+			t.Errorf("checkRanking(%q) = nil, want an error", ranking)
+		}
+	}
+}
+
+func TestHandicap(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.Cars = map[string]string{"42": "Spitfire", "7": "TR6"}
+	cfg.Handicaps = map[string]float64{"Spitfire": 1.1}
+
+	const laps = "  1:10.0000 -:--.---- 1:20.0000 1:04.0000 1:06.0000 "
+	tests := []struct {
+		raceNumber string
+		handicap   float64
+	}{
+		{"42", 1.1},
+		{"7", 1}, // Not listed in the handicaps.
+		{"12", 1},
+	}
+
+	for _, test := range tests {
+		entry := Entry{RaceNumber: test.raceNumber}
+		driver := Driver{RaceNumber: test.raceNumber, Car: entry.car(), Timing: parseLaps([]byte(" " + test.raceNumber + " Joe Bloggs" + laps))}
+		driver.score()
+		if driver.Handicap != test.handicap || driver.Percentage <= 0 || driver.Score != driver.Percentage*test.handicap {
+			t.Errorf("car %s: got handicap %v, percentage %v and score %v, want handicap %v", test.raceNumber, driver.Handicap, driver.Percentage, driver.Score, test.handicap)
+		}
+	}
+}
+
+// natsoftResults is laid out like the Natsoft results page, where the lap counter 10 is listed after the first ten laps of each car.
+const natsoftResults = `Sports Car Track Day
+ 42 Joe Bloggs  1:10.1234 1:05.4321 1:04.8250 -:--.---- 1:20.0000 1:06.0000 2:10.4010 1:08.2000 -:--.---- 1:30.0000 
+               10 1:07.0000 0:33.0000 0:34.5000 1:09.5000 
+ 512 Sam Smith  1:15.0000 1:10.2260 1:11.0000 -:--.---- 1:25.0000 1:12.0000 -:--.---- 1:30.9720 1:11.0000 
+               10 1:20.0000 1:10.0000 
+`
+
+func TestParseNatsoft(t *testing.T) {
+	drivers := parseNatsoft([]byte(natsoftResults))
+	if len(drivers) != 2 {
+		t.Fatalf("got %d drivers, want 2", len(drivers))
+	}
+
+	tests := []struct {
+		raceNumber, name string
+		natsoft          []uint
+		runs             []uint
+		statuses         []string
+	}{
+		{
+			raceNumber: "42",
+			name:       "Joe Bloggs",
+			natsoft:    []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+			runs:       []uint{0, 0, 0, 0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2},
+			statuses: []string{
+				lapQualifying, lapQualifying, lapQualifying, lapExcluded,
+				lapFormation, lapCounted, lapCounted, lapCounted, lapExcluded,
+				lapFormation, lapCounted, lapCounted, lapCounted, lapCounted,
+			},
+		},
+		{
+			// The 10th lap time wasn't copied, so the lap counter realigns the laps that follow.
+			raceNumber: "512",
+			name:       "Sam Smith",
+			natsoft:    []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 12},
+			runs:       []uint{0, 0, 0, 0, 1, 1, 1, 2, 2, 2, 2},
+			statuses: []string{
+				lapQualifying, lapQualifying, lapQualifying, lapExcluded,
+				lapFormation, lapCounted, lapExcluded,
+				lapFormation, lapCounted, lapCounted, lapCounted,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		d := &drivers[i]
+		if d.RaceNumber != test.raceNumber || d.Name != test.name {
+			t.Errorf("driver %d: got %q %q, want %q %q", i, d.RaceNumber, d.Name, test.raceNumber, test.name)
+		}
+
+		var natsoft, runs []uint
+		var statuses []string
+		for _, lap := range d.Timing {
+			natsoft = append(natsoft, lap.Natsoft)
+			runs = append(runs, lap.Run)
+			statuses = append(statuses, lap.Status)
+		}
+		if !reflect.DeepEqual(natsoft, test.natsoft) {
+			t.Errorf("car %s: got Natsoft lap numbers %v, want %v", d.RaceNumber, natsoft, test.natsoft)
+		}
+		if !reflect.DeepEqual(runs, test.runs) {
+			t.Errorf("car %s: got runs %v, want %v", d.RaceNumber, runs, test.runs)
+		}
+		if !reflect.DeepEqual(statuses, test.statuses) {
+			t.Errorf("car %s: got statuses %v, want %v", d.RaceNumber, statuses, test.statuses)
+		}
+	}
+
+	// The lap after the counter is the 11th lap shown on the Natsoft page.
+	if lap := drivers[0].Timing[10]; lap.Natsoft != 11 || lap.Number != 2 || lap.Time != 1*time.Minute+7*time.Second {
+		t.Errorf("got lap %+v, want Natsoft lap 11, the 2nd lap of run 2 in 1m7s", lap)
+	}
+}
+
+func TestLapGrid(t *testing.T) {
+	tests := []struct {
+		line    string
+		natsoft []uint
+	}{
+		{" 7 Ann Lee  1:05.0000 1:06.0000", []uint{1, 2}},
+		// A racing number of 10 isn't a lap counter.
+		{" 10 Ann Lee  1:05.0000 1:06.0000", []uint{1, 2}},
+		{" 7 Ann Lee  1:05.0000 -:--.---- 1:06.0000 \n               10 1:07.0000 \n               20 1:08.0000", []uint{1, 2, 3, 11, 21}},
+		{" 7 Ann Lee  ", nil},
+	}
+
+	for _, test := range tests {
+		lapTimes, natsoft := lapGrid([]byte(test.line))
+		if !reflect.DeepEqual(natsoft, test.natsoft) || len(lapTimes) != len(test.natsoft) {
+			t.Errorf("lapGrid(%q) = %q %v, want lap numbers %v", test.line, lapTimes, natsoft, test.natsoft)
+		}
+	}
+}
+
+func TestSortResultsIneligible(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.RequireQualify, cfg.MinRuns, cfg.MinLaps, cfg.Progression = true, 1, 1, false
+
+	cars := []Driver{
+		{RaceNumber: "1", Name: "Ann Lee", Timing: append(timing(0, 66), timing(1, 65)...)},
+		{RaceNumber: "2", Name: "Bob Day", Timing: append(timing(0, 67), timing(1, 66)...)},
+	}
+	entries := []Entry{
+		{RaceNumber: "1"},
+		{RaceNumber: "2", Unpaid: true},
+		{RaceNumber: "3", Name: "Cal Roe", NonMember: true},
+		{RaceNumber: "4"},
+	}
+
+	var adjustments []Adjustment
+	event := sortResults(cars, Meta{}, entries, &adjustments, nil)
+	if len(event.Drivers) != 1 || event.Drivers[0].RaceNumber != "1" {
+		t.Errorf("got drivers %v, want car 1", event.Drivers)
+	}
+	if len(event.Ineligible) != 1 || event.Ineligible[0].RaceNumber != "2" || event.Ineligible[0].Reason != "Entry not paid" {
+		t.Errorf("got ineligible %v, want car 2", event.Ineligible)
+	}
+	if want := []string{"3 Cal Roe - Membership not current"}; !reflect.DeepEqual(event.Absent, want) {
+		t.Errorf("got absent %q, want %q", event.Absent, want)
+	}
+	if want := []string{"4"}; !reflect.DeepEqual(event.Missing, want) {
+		t.Errorf("got missing %q, want %q", event.Missing, want)
+	}
+}
+
+func TestIneligible(t *testing.T) {
+	tests := []struct {
+		entry  Entry
+		reason string
+	}{
+		{Entry{RaceNumber: "1"}, ""},
+		{Entry{RaceNumber: "2", Unpaid: true}, "Entry not paid"},
+		{Entry{RaceNumber: "3", NonMember: true, IneligibleCar: true}, "Membership not current, Car not eligible"},
+	}
+
+	for _, test := range tests {
+		if got := test.entry.ineligible(); got != test.reason {
+			t.Errorf("car %s: ineligible() = %q, want %q", test.entry.RaceNumber, got, test.reason)
+		}
+	}
+}
+
+func TestShare(t *testing.T) {
+	car := Driver{RaceNumber: "47", Name: "Jack Black / Jill Black", Timing: append(append(timing(0, 66), timing(1, 65, 67)...), timing(2, 64, 70)...)}
+	car.score()
+	entries := []Entry{
+		{RaceNumber: "1"},
+		{RaceNumber: "47", Name: "Jack Black", Runs: []uint{0, 1}},
+		{RaceNumber: "47", Name: "Jill Black", Runs: []uint{0, 2}, Unpaid: true},
+	}
+
+	drivers := car.share(entries)
+	if len(drivers) != 2 {
+		t.Fatalf("got %d drivers, want 2", len(drivers))
+	}
+	tests := []struct {
+		name    string
+		fastest time.Duration
+		laps    uint
+		reason  string
+	}{
+		{"Jack Black", 65 * time.Second, 2, ""},
+		{"Jill Black", 64 * time.Second, 2, "Entry not paid"},
+	}
+	for i, test := range tests {
+		d := &drivers[i]
+		if d.Name != test.name || d.Fastest != test.fastest || d.Laps != test.laps || d.Runs != 1 || d.Reason != test.reason {
+			t.Errorf("driver %d: got %q fastest %s, %d laps, %d runs, reason %q, want %q fastest %s, %d laps, 1 run, reason %q",
+				i, d.Name, d.Fastest, d.Laps, d.Runs, d.Reason, test.name, test.fastest, test.laps, test.reason)
+		}
+	}
+
+	// A car that isn't shared keeps its name and laps.
+	car.RaceNumber = "1"
+	if drivers = car.share(entries); len(drivers) != 1 || drivers[0].Name != car.Name || drivers[0].Laps != 4 {
+		t.Errorf("got %v, want the car's results", drivers)
+	}
+}
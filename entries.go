@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// EntryColumns contains the column headings of the online entry system's CSV export.
+// Headings are matched case-insensitively. Leave a heading empty when the export doesn't contain it.
+type EntryColumns struct {
+	RaceNumber string `json:"raceNumber"` // Required.
+	Driver     string `json:"driver"`
+	Car        string `json:"car"`
+	Class      string `json:"class"`
+	Paid       string `json:"paid"`     // When set, entries not marked as paid aren't eligible to be scored.
+	Member     string `json:"member"`   // When set, entries not marked as a current club member aren't eligible to be scored.
+	Eligible   string `json:"eligible"` // When set, entries not marked as having an eligible car aren't eligible to be scored.
+}
+
+// importEntries returns the competitors in the online entry system's CSV export and saves them as the event's competitor list.
+func importEntries(fileName string, meta *Meta) (entries []Entry) {
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		fmt.Println("Unable to read entries", err)
+		return nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(src, []byte("\xef\xbb\xbf")))) // Ignore any UTF-8 byte order mark.
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		fmt.Println("Unable to read entries", fileName, err)
+		return nil
+	}
+
+	columns := entryColumnIndexes(header)
+	if columns.raceNumber < 0 {
+		fmt.Printf("%s doesn't contain the %q column. Please check the `entryColumns` setting.\n", fileName, cfg.EntryColumns.RaceNumber)
+		return nil
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(fileName, err)
+			continue
+		}
+
+		line, _ := r.FieldPos(0)
+		entry := Entry{
+			RaceNumber: column(record, columns.raceNumber),
+			Name:       column(record, columns.driver),
+			Car:        column(record, columns.car),
+			Class:      column(record, columns.class),
+
+			Unpaid:        columns.paid >= 0 && !isTicked(column(record, columns.paid)),
+			NonMember:     columns.member >= 0 && !isTicked(column(record, columns.member)),
+			IneligibleCar: columns.eligible >= 0 && !isTicked(column(record, columns.eligible)),
+		}
+		switch m := reEntryNumbers.FindStringSubmatch(entry.RaceNumber); {
+		case entry.RaceNumber == "":
+			continue
+		case m == nil || m[1] != "" || m[3] != "":
+			fmt.Printf("%s line %d: invalid racing number %q\n", fileName, line, entry.RaceNumber)
+			continue
+		}
+
+		// Keep the first entry of a racing number, so a duplicate can't silently replace it.
+		if i := indexOfEntry(entries, entry.RaceNumber); i >= 0 {
+			fmt.Printf("%s line %d: ignoring %s because racing number %s is already entered by %s\n", fileName, line, entry.String(), entry.RaceNumber, entries[i].String())
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	fmt.Printf("Imported %d competitors from %s%s", len(entries), fileName, newLine)
+	for i := range entries {
+		if reason := entries[i].ineligible(); reason != "" {
+			fmt.Printf("%s is ineligible: %s%s", entries[i].String(), reason, newLine)
+		}
+	}
+
+	if len(entries) >= 1 {
+		saveCompetitors(meta, entries)
+	}
+
+	return entries
+}
+
+// entryColumns contains the index of each column in the entry CSV, or -1 when not found.
+type entryColumns struct {
+	raceNumber, driver, car, class, paid, member, eligible int
+}
+
+// entryColumnIndexes returns the index of each column in header named by the `entryColumns` setting.
+func entryColumnIndexes(header []string) entryColumns {
+	index := func(heading string) int {
+		if heading == "" {
+			return -1
+		}
+		for i := range header {
+			if strings.EqualFold(strings.TrimSpace(header[i]), strings.TrimSpace(heading)) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	return entryColumns{
+		raceNumber: index(cfg.EntryColumns.RaceNumber),
+		driver:     index(cfg.EntryColumns.Driver),
+		car:        index(cfg.EntryColumns.Car),
+		class:      index(cfg.EntryColumns.Class),
+		paid:       index(cfg.EntryColumns.Paid),
+		member:     index(cfg.EntryColumns.Member),
+		eligible:   index(cfg.EntryColumns.Eligible),
+	}
+}
+
+// column returns the trimmed value in the record's column, or an empty string when the column isn't present.
+func column(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+// isTicked returns true when the entry system's status represents a paid entry, a current membership or an eligible car.
+func isTicked(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "y", "yes", "true", "1", "x", "paid", "complete", "completed", "current", "financial", "eligible":
+		return true
+	}
+
+	return false
+}
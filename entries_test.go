@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEntryColumnIndexes(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.EntryColumns = EntryColumns{RaceNumber: "Car No", Driver: "Name", Paid: "paid "}
+
+	got := entryColumnIndexes([]string{"Name", " car no ", "Car", "Paid"})
+	if want := (entryColumns{raceNumber: 1, driver: 0, car: -1, class: -1, paid: 3, member: -1, eligible: -1}); got != want {
+		t.Errorf("entryColumnIndexes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestIsTicked(t *testing.T) {
+	for _, status := range []string{"Y", "yes", "TRUE", "1", "x", " Paid ", "Completed"} {
+		if !isTicked(status) {
+			t.Errorf("isTicked(%q) = false, want true", status)
+		}
+	}
+	for _, status := range []string{"", "n", "no", "0", "pending", "unpaid"} {
+		if isTicked(status) {
+			t.Errorf("isTicked(%q) = true, want false", status)
+		}
+	}
+}
+
+func TestImportEntries(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	fileName, err := filepath.Abs(filepath.Join("testdata", "entries.csv"))
+	checkErr(err)
+
+	// The competitor list is saved in the working directory.
+	wd, err := os.Getwd()
+	checkErr(err)
+	checkErr(os.Chdir(t.TempDir()))
+	defer func() { checkErr(os.Chdir(wd)) }()
+
+	meta := Meta{Title: "Sports Car Track Day"}
+	entries := importEntries(fileName, &meta)
+	want := []Entry{
+		{RaceNumber: "47", Name: "Jack Black", Car: "Triumph Spitfire Mk3", Class: "Spitfire"},
+		{RaceNumber: "48", Name: "Lee, Ann", Car: "Triumph TR6", Unpaid: true},
+		{RaceNumber: "7", Name: "Eve Adams", Car: "Triumph Stag"},
+		// The duplicate entry of racing number 47 is ignored.
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("importEntries() =\n%+v\nwant\n%+v", entries, want)
+	}
+
+	// The saved competitor list is read back the same.
+	src, err := ioutil.ReadFile(competitorsFileName(&meta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, errs := parseCompetitors(src); !reflect.DeepEqual(saved, want) || len(errs) != 0 {
+		t.Errorf("saved competitors = %+v %q, want %+v", saved, errs, want)
+	}
+
+	// Without the racing number column.
+	cfg.EntryColumns.RaceNumber = "Number"
+	if entries := importEntries(fileName, &meta); entries != nil {
+		t.Errorf("importEntries() without a racing number column = %+v, want nil", entries)
+	}
+}
@@ -3,97 +3,398 @@ package main
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
-const worksheet = "Sheet1"
+const (
+	worksheet     = "Sheet1"
+	runsWorksheet = "Runs"
+	lapsWorksheet = "Laps"
+	progWorksheet = "Progression"
 
-func excelHeading(eventName string) (f *excelize.File, row int) {
+	// Defined names referring to the Laps worksheet columns.
+	nameLapNumbers  = "LapRacingNumbers"
+	nameLapDrivers  = "LapDrivers"
+	nameLapSeconds  = "LapSeconds"
+	nameLapStatuses = "LapStatuses"
+)
+
+func excelHeading(eventName string, meta *Meta, sources bool) (f *excelize.File, row int) {
 	f = excelize.NewFile()
 	row = 1
-	excelStr(f, &row, "A", eventName)
-	checkErr(f.MergeCell(worksheet, "A1", "M1"))
-	style, err := f.NewStyle(`{"alignment":{"horizontal":"center"},"font":{"bold":true,"size":16,"color":"#FF8800"}}`)
-	checkErr(err)
-	checkErr(f.SetCellStyle(worksheet, "A1", "M1", style))
+	excelStr(f, worksheet, &row, "A", eventName)
 
-	row++
-	excelStr(f, &row, "A", hPosition)
+	// List the event details below the event name, like the HTML results.
+	if subtitle := meta.Subtitle(); subtitle != "" {
+		row++
+		excelStr(f, worksheet, &row, "A", subtitle)
+	}
+	if len(meta.Sessions) >= 1 {
+		row++
+		excelStr(f, worksheet, &row, "A", hSessions+" "+strings.Join(meta.Sessions, ", "))
+	}
 
 	row++
-	// Create worksheet column headings in the second row.
-	excelStr(f, &row, "A", hPosition)
-	excelStr(f, &row, "B", hRacingNumber)
-	excelStr(f, &row, "C", hDriver)
-	excelStr(f, &row, "D", hQualify)
-	excelStr(f, &row, "E", hSeconds)
-	excelStr(f, &row, "F", hFastest)
-	excelStr(f, &row, "G", hSeconds)
-	excelStr(f, &row, "H", hSlowest)
-	excelStr(f, &row, "I", hSeconds)
-	excelStr(f, &row, "J", hAverage)
-	excelStr(f, &row, "K", hPercentage)
-	excelStr(f, &row, "L", hRuns)
-	excelStr(f, &row, "M", hLaps)
+	// Create worksheet column headings below the event details.
+	excelStr(f, worksheet, &row, "A", hPosition)
+	excelStr(f, worksheet, &row, "B", hRacingNumber)
+	excelStr(f, worksheet, &row, "C", hDriver)
+	excelStr(f, worksheet, &row, "D", hQualify)
+	excelStr(f, worksheet, &row, "E", hSeconds)
+	excelStr(f, worksheet, &row, "F", hFastest)
+	excelStr(f, worksheet, &row, "G", hSeconds)
+	excelStr(f, worksheet, &row, "H", hSlowest)
+	excelStr(f, worksheet, &row, "I", hSeconds)
+	excelStr(f, worksheet, &row, "J", hAverage)
+	excelStr(f, worksheet, &row, "K", hPercentage)
+
+	// Optional columns are inserted after the Percentage column.
+	column := "K"
+	var headings []string
+	if cfg.hasHandicaps() {
+		headings = append(headings, hCar, hHandicap, hScore)
+	}
+	if cfg.Consistency {
+		headings = append(headings, hMean, hMedian, hStdDev, hCV)
+	}
+	if cfg.Progression {
+		headings = append(headings, hChange)
+	}
+	headings = append(headings, hRuns, hLaps)
+	if sources {
+		headings = append(headings, hSource)
+	}
+
+	for _, heading := range headings {
+		column = nextColumn(column)
+		excelStr(f, worksheet, &row, column, heading)
+	}
+
+	// Merge the event name across all column headings.
+	checkErr(f.MergeCell(worksheet, "A1", column+"1"))
+	style, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Font:      &excelize.Font{Bold: true, Size: 16, Color: "#FF8800"},
+	})
+	checkErr(err)
+	checkErr(f.SetCellStyle(worksheet, "A1", column+"1", style))
 
 	return f, row
 }
 
-// excelRow populates spreadsheet cells.
-func excelRow(f *excelize.File, d *Driver, ordinal string, row *int) {
+// excelRow populates spreadsheet cells, with the sources of any laps not from the timing system when sources is true.
+func excelRow(f *excelize.File, d *Driver, ordinal string, row *int, sources bool) {
 	*row++
 
-	excelStr(f, row, "A", ordinal)
-	excelStr(f, row, "B", d.RaceNumber)
-	excelStr(f, row, "C", d.Name)
-	excelStr(f, row, "D", d.Qualify.String())
-	excelFloat(f, row, "E", d.Qualify.Seconds())
-	excelStr(f, row, "F", d.Fastest.String())
-	excelFloat(f, row, "G", d.Fastest.Seconds())
-	excelStr(f, row, "H", d.Slowest.String())
-	excelFloat(f, row, "I", d.Slowest.Seconds())
+	excelStr(f, worksheet, row, "A", ordinal)
+	excelStr(f, worksheet, row, "B", d.RaceNumber)
+	excelStr(f, worksheet, row, "C", d.Name)
+	excelStr(f, worksheet, row, "D", d.Qualify.String())
+	excelFloat(f, worksheet, row, "E", d.Qualify.Seconds())
+	excelStr(f, worksheet, row, "F", d.Fastest.String())
+	excelFloat(f, worksheet, row, "G", d.Fastest.Seconds())
+	excelStr(f, worksheet, row, "H", d.Slowest.String())
+	excelFloat(f, worksheet, row, "I", d.Slowest.Seconds())
 
 	// Slow Average equals d.Qualify.Seconds() + d.Slowest.Seconds() / 2.
-	excelFormula(f, row, "J", fmt.Sprintf("(E%d+I%[1]d)/2", *row))
+	excelFormula(f, worksheet, row, "J", fmt.Sprintf("(E%d+I%[1]d)/2", *row))
 
 	// Percentage equals d.Fastest.Seconds() / ((d.Slowest.Seconds() + d.Qualify.Seconds()) / 2) * 100.
-	excelFormula(f, row, "K", fmt.Sprintf("G%d/J%[1]d * 100", *row))
+	excelFormula(f, worksheet, row, "K", fmt.Sprintf("G%d/J%[1]d * 100", *row))
+
+	column := "K"
+	if cfg.hasHandicaps() {
+		column = nextColumn(column)
+		excelStr(f, worksheet, row, column, d.Car)
+		column = nextColumn(column)
+		excelFloat(f, worksheet, row, column, d.Handicap)
+
+		// Handicapped equals d.Percentage * d.Handicap.
+		excelFormula(f, worksheet, row, nextColumn(column), fmt.Sprintf("K%d*%s%[1]d", *row, column))
+		column = nextColumn(column)
+	}
+
+	if cfg.Consistency {
+		// Select the Laps worksheet lap times counted for this driver. Drivers sharing a car have the same racing number, so their names are compared too.
+		counted := fmt.Sprintf(`IF((%s=B%d)*(%s=C%[2]d)*(%s="%s"),%s)`, nameLapNumbers, *row, nameLapDrivers, nameLapStatuses, lapCounted, nameLapSeconds)
+
+		column = nextColumn(column)
+		mean := column
+		excelFormula(f, worksheet, row, column, fmt.Sprintf(`IFERROR(AVERAGEIFS(%s,%s,B%d,%s,C%[3]d,%s,"%s"),0)`, nameLapSeconds, nameLapNumbers, *row, nameLapDrivers, nameLapStatuses, lapCounted))
+		column = nextColumn(column)
+		excelArrayFormula(f, worksheet, row, column, fmt.Sprintf("IFERROR(MEDIAN(%s),0)", counted))
+		column = nextColumn(column)
+		stdDev := column
+		excelArrayFormula(f, worksheet, row, column, fmt.Sprintf("IFERROR(STDEV(%s),0)", counted))
+
+		// Coefficient of variation equals d.StdDev / d.Mean * 100.
+		column = nextColumn(column)
+		excelFormula(f, worksheet, row, column, fmt.Sprintf("IF(%s%d=0,0,%s%[2]d/%[1]s%[2]d*100)", mean, *row, stdDev))
+	}
+
+	if cfg.Progression {
+		column = nextColumn(column)
+		excelStr(f, worksheet, row, column, d.positionChange())
+	}
+
+	column = nextColumn(column)
+	excelInt(f, worksheet, row, column, d.Runs)
+	column = nextColumn(column)
+	excelInt(f, worksheet, row, column, d.Laps)
+	if sources {
+		excelStr(f, worksheet, row, nextColumn(column), d.Sources)
+	}
+}
+
+// excelReasons lists the drivers excluded from the results under heading, with the reason why.
+func excelReasons(f *excelize.File, row *int, heading string, drivers []Driver) {
+	if len(drivers) == 0 {
+		return
+	}
+
+	*row += 2
+	excelStr(f, worksheet, row, "A", heading)
+	*row++
+	excelStr(f, worksheet, row, "B", hRacingNumber)
+	excelStr(f, worksheet, row, "C", hDriver)
+	excelStr(f, worksheet, row, "D", hReason)
+	for i := range drivers {
+		*row++
+		excelStr(f, worksheet, row, "B", drivers[i].RaceNumber)
+		excelStr(f, worksheet, row, "C", drivers[i].Name)
+		excelStr(f, worksheet, row, "D", drivers[i].Reason)
+	}
+}
+
+// excelRuns adds a worksheet containing the statistics of each run.
+func excelRuns(f *excelize.File, drivers []Driver) {
+	_, err := f.NewSheet(runsWorksheet)
+	checkErr(err)
+
+	row := 1
+	excelStr(f, runsWorksheet, &row, "A", hRacingNumber)
+	excelStr(f, runsWorksheet, &row, "B", hDriver)
+	excelStr(f, runsWorksheet, &row, "C", hRun)
+	excelStr(f, runsWorksheet, &row, "D", hLaps)
+	excelStr(f, runsWorksheet, &row, "E", hFastest)
+	excelStr(f, runsWorksheet, &row, "F", hSeconds)
+	excelStr(f, runsWorksheet, &row, "G", hSlowest)
+	excelStr(f, runsWorksheet, &row, "H", hSeconds)
+	excelStr(f, runsWorksheet, &row, "I", hAverageLap)
+	excelStr(f, runsWorksheet, &row, "J", hSeconds)
+	excelStr(f, runsWorksheet, &row, "K", hSource)
+
+	for i := range drivers {
+		for r := range drivers[i].RunStats {
+			if !drivers[i].drove(uint(r)) {
+				continue
+			}
+			run := &drivers[i].RunStats[r]
+			row++
+			excelStr(f, runsWorksheet, &row, "A", drivers[i].RaceNumber)
+			excelStr(f, runsWorksheet, &row, "B", drivers[i].Name)
+			excelStr(f, runsWorksheet, &row, "C", runName(r))
+			excelInt(f, runsWorksheet, &row, "D", run.Laps)
+			excelStr(f, runsWorksheet, &row, "E", run.Fastest.String())
+			excelFloat(f, runsWorksheet, &row, "F", run.Fastest.Seconds())
+			excelStr(f, runsWorksheet, &row, "G", run.Slowest.String())
+			excelFloat(f, runsWorksheet, &row, "H", run.Slowest.Seconds())
+			excelStr(f, runsWorksheet, &row, "I", run.Average().String())
+			excelFloat(f, runsWorksheet, &row, "J", run.Average().Seconds())
+			excelStr(f, runsWorksheet, &row, "K", run.Source)
+		}
+	}
+}
+
+// excelLaps adds a worksheet containing every lap parsed.
+func excelLaps(f *excelize.File, drivers []Driver) {
+	_, err := f.NewSheet(lapsWorksheet)
+	checkErr(err)
+
+	row := 1
+	excelStr(f, lapsWorksheet, &row, "A", hRacingNumber)
+	excelStr(f, lapsWorksheet, &row, "B", hDriver)
+	excelStr(f, lapsWorksheet, &row, "C", hLap)
+	excelStr(f, lapsWorksheet, &row, "D", hRun)
+	excelStr(f, lapsWorksheet, &row, "E", hRunLap)
+	excelStr(f, lapsWorksheet, &row, "F", hSeconds)
+	excelStr(f, lapsWorksheet, &row, "G", hStatus)
+	excelStr(f, lapsWorksheet, &row, "H", hNote)
+	excelStr(f, lapsWorksheet, &row, "I", hSource)
+
+	for i := range drivers {
+		for l := range drivers[i].Timing {
+			lap := &drivers[i].Timing[l]
+			row++
+			excelStr(f, lapsWorksheet, &row, "A", drivers[i].RaceNumber)
+			excelStr(f, lapsWorksheet, &row, "B", drivers[i].Name)
+			excelInt(f, lapsWorksheet, &row, "C", lap.Natsoft)
+			excelInt(f, lapsWorksheet, &row, "D", lap.Run)
+			excelInt(f, lapsWorksheet, &row, "E", lap.Number)
+			if lap.Status != lapExcluded {
+				excelFloat(f, lapsWorksheet, &row, "F", lap.Time.Seconds())
+			}
+			excelStr(f, lapsWorksheet, &row, "G", lap.Status)
+			excelStr(f, lapsWorksheet, &row, "H", lap.Note)
+			excelStr(f, lapsWorksheet, &row, "I", lap.Source)
+		}
+	}
 
-	excelInt(f, row, "L", d.Runs)
-	excelInt(f, row, "M", d.Laps)
+	// Name the lap columns so formulas on other worksheets remain readable.
+	for _, n := range [][2]string{{nameLapNumbers, "A"}, {nameLapDrivers, "B"}, {nameLapSeconds, "F"}, {nameLapStatuses, "G"}} {
+		checkErr(f.SetDefinedName(&excelize.DefinedName{
+			Name:     n[0],
+			RefersTo: fmt.Sprintf("%s!$%s$2:$%[2]s$%d", lapsWorksheet, n[1], row),
+		}))
+	}
 }
 
-func excelFooter(xlsx *excelize.File, spreadsheetRow *int, missingCars []string) {
-	if len(missingCars) == 0 {
+// excelProgression adds a worksheet containing each driver's position after each run.
+func excelProgression(f *excelize.File, drivers []Driver) {
+	if len(drivers) == 0 {
+		return
+	}
+
+	_, err := f.NewSheet(progWorksheet)
+	checkErr(err)
+
+	row := 1
+	excelStr(f, progWorksheet, &row, "A", hPosition)
+	excelStr(f, progWorksheet, &row, "B", hRacingNumber)
+	excelStr(f, progWorksheet, &row, "C", hDriver)
+	column := "C"
+	for r := range drivers[0].Progress {
+		column = nextColumn(column)
+		excelStr(f, progWorksheet, &row, column, progressName(r))
+	}
+	excelStr(f, progWorksheet, &row, nextColumn(column), hChange)
+
+	for i := range drivers {
+		row++
+		excelStr(f, progWorksheet, &row, "A", drivers[i].Ordinal())
+		excelStr(f, progWorksheet, &row, "B", drivers[i].RaceNumber)
+		excelStr(f, progWorksheet, &row, "C", drivers[i].Name)
+		column = "C"
+		for _, standing := range drivers[i].Progress {
+			column = nextColumn(column)
+			excelStr(f, progWorksheet, &row, column, standing.Ordinal())
+		}
+		excelStr(f, progWorksheet, &row, nextColumn(column), drivers[i].positionChange())
+	}
+}
+
+func excelAnalysis(f *excelize.File, row *int, analyses []Analysis) {
+	if len(analyses) == 0 {
+		return
+	}
+
+	*row += 2
+	excelStr(f, worksheet, row, "A", hAnalysis)
+	*row++
+	excelStr(f, worksheet, row, "A", hPosition)
+	excelStr(f, worksheet, row, "B", hRacingNumber)
+	excelStr(f, worksheet, row, "C", hDriver)
+	excelStr(f, worksheet, row, "D", hGapAhead)
+	excelStr(f, worksheet, row, "E", hGapLeader)
+	excelStr(f, worksheet, row, "F", hFastestNeeded)
+	excelStr(f, worksheet, row, "G", hSeconds)
+	excelStr(f, worksheet, row, "H", hSlowestNeeded)
+	excelStr(f, worksheet, row, "I", hSeconds)
+
+	for i := range analyses {
+		a := &analyses[i]
+		*row++
+		excelStr(f, worksheet, row, "A", a.Driver.Ordinal())
+		excelStr(f, worksheet, row, "B", a.Driver.RaceNumber)
+		excelStr(f, worksheet, row, "C", a.Driver.Name)
+		excelFloat(f, worksheet, row, "D", a.GapAhead)
+		excelFloat(f, worksheet, row, "E", a.GapLeader)
+		excelStr(f, worksheet, row, "F", needed(a.Fastest))
+		if a.Fastest > 0 {
+			excelFloat(f, worksheet, row, "G", a.Fastest.Seconds())
+		}
+		excelStr(f, worksheet, row, "H", needed(a.Slowest))
+		if a.Slowest > 0 {
+			excelFloat(f, worksheet, row, "I", a.Slowest.Seconds())
+		}
+	}
+}
+
+func excelAwards(f *excelize.File, row *int, awards []Award) {
+	if len(awards) == 0 {
+		return
+	}
+
+	*row += 2
+	excelStr(f, worksheet, row, "A", hAwards)
+	for i := range awards {
+		*row++
+		excelStr(f, worksheet, row, "A", awards[i].Heading)
+		excelStr(f, worksheet, row, "C", awards[i].String())
+	}
+}
+
+func excelDiagnostics(f *excelize.File, row *int, diagnostics []Diagnostic) {
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	*row += 2
+	excelStr(f, worksheet, row, "A", hDiagnostics)
+	for i := range diagnostics {
+		*row++
+		excelStr(f, worksheet, row, "A", diagnostics[i].Name())
+		excelStr(f, worksheet, row, "C", diagnostics[i].Message)
+	}
+}
+
+func excelFooter(xlsx *excelize.File, spreadsheetRow *int, heading string, cars []string) {
+	if len(cars) == 0 {
 		return
 	}
 
 	*spreadsheetRow += 2
-	checkErr(xlsx.SetCellStr(worksheet, axis(spreadsheetRow, "A"), hMissing))
-	for i := range missingCars {
+	checkErr(xlsx.SetCellStr(worksheet, axis(spreadsheetRow, "A"), heading))
+	for i := range cars {
 		*spreadsheetRow++
-		checkErr(xlsx.SetCellStr(worksheet, axis(spreadsheetRow, "A"), missingCars[i]))
+		checkErr(xlsx.SetCellStr(worksheet, axis(spreadsheetRow, "A"), cars[i]))
 	}
 }
 
-func excelStr(f *excelize.File, spreadsheetRow *int, column, value string) {
-	checkErr(f.SetCellStr(worksheet, axis(spreadsheetRow, column), value))
+func excelStr(f *excelize.File, sheet string, spreadsheetRow *int, column, value string) {
+	checkErr(f.SetCellStr(sheet, axis(spreadsheetRow, column), value))
 }
 
-func excelFloat(f *excelize.File, spreadsheetRow *int, column string, value float64) {
+func excelFloat(f *excelize.File, sheet string, spreadsheetRow *int, column string, value float64) {
 	const bitSize = 64 // Float64 precision.
-	checkErr(f.SetCellDefault(worksheet, axis(spreadsheetRow, column), strconv.FormatFloat(value, 'f', decimalPlaces, bitSize)))
+	checkErr(f.SetCellDefault(sheet, axis(spreadsheetRow, column), strconv.FormatFloat(value, 'f', decimalPlaces, bitSize)))
+}
+
+func excelFormula(f *excelize.File, sheet string, spreadsheetRow *int, column, value string) {
+	checkErr(f.SetCellFormula(sheet, axis(spreadsheetRow, column), value))
 }
 
-func excelFormula(f *excelize.File, spreadsheetRow *int, column, value string) {
-	checkErr(f.SetCellFormula(worksheet, axis(spreadsheetRow, column), value))
+// excelArrayFormula sets a formula that is evaluated over arrays, like `{=MEDIAN(IF(...))}`.
+func excelArrayFormula(f *excelize.File, sheet string, spreadsheetRow *int, column, value string) {
+	formulaType, ref := excelize.STCellFormulaTypeArray, axis(spreadsheetRow, column)
+	checkErr(f.SetCellFormula(sheet, ref, value, excelize.FormulaOpts{Type: &formulaType, Ref: &ref}))
 }
 
-func excelInt(f *excelize.File, spreadsheetRow *int, column string, value uint) {
-	checkErr(f.SetCellInt(worksheet, axis(spreadsheetRow, column), int(value)))
+func excelInt(f *excelize.File, sheet string, spreadsheetRow *int, column string, value uint) {
+	checkErr(f.SetCellInt(sheet, axis(spreadsheetRow, column), int(value)))
 }
 
 func axis(row *int, column string) string {
 	return fmt.Sprintf("%s%d", column, *row)
 }
+
+// nextColumn returns the column name to the right of column, like "Z" returns "AA".
+func nextColumn(column string) string {
+	n, err := excelize.ColumnNameToNumber(column)
+	checkErr(err)
+	column, err = excelize.ColumnNumberToName(n + 1)
+	checkErr(err)
+	return column
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestExcelHeading(t *testing.T) {
+	tests := []struct {
+		meta Meta
+		want []string // Values of column A from the second row, ending with the first column heading.
+	}{
+		{
+			Meta{Circuit: "Winton Motor Raceway", Club: "TSOA", Sessions: []string{"Qualifying", "Run 1"}},
+			[]string{"Winton Motor Raceway - TSOA", hSessions + " Qualifying, Run 1", hPosition},
+		},
+		{Meta{Club: "TSOA"}, []string{"TSOA", hPosition}},
+		{Meta{}, []string{hPosition}},
+	}
+
+	for _, test := range tests {
+		f, row := excelHeading("Sports Car Track Day", &test.meta, false)
+		// The column headings are on the returned row.
+		if want := len(test.want) + 1; row != want {
+			t.Errorf("excelHeading(%+v) row = %d, want %d", test.meta, row, want)
+		}
+		for i, want := range test.want {
+			got, err := f.GetCellValue(worksheet, "A"+strconv.Itoa(i+2))
+			if err != nil || got != want {
+				t.Errorf("excelHeading(%+v) A%d = %q, %v, want %q", test.meta, i+2, got, err, want)
+			}
+		}
+	}
+}
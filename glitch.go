@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adjustmentsPrefix is the start of each event's adjustments file name, see Meta.eventFileName.
+const adjustmentsPrefix = "adjustments"
+
+// Adjustment actions correcting transponder glitches.
+const (
+	adjustSplit = "split" // Split a lap about double the median lap time into two laps, when the transponder missed the line.
+	adjustMerge = "merge" // Merge a short lap with the next lap, when the transponder crossed the line an extra time.
+)
+
+// Adjustment decisions made by officials.
+const (
+	decisionPending = "pending"
+	decisionApprove = "approve"
+	decisionReject  = "reject"
+)
+
+// Adjustment is a suggested correction to a driver's lap, approved or rejected by officials in the event's adjustments file.
+type Adjustment struct {
+	RaceNumber string
+	Lap        uint // Lap number shown on the Natsoft page.
+	Action     string
+	Decision   string
+	Comment    string // Why the adjustment was suggested.
+	isNew      bool   // Suggested during this event, not yet saved to the adjustments file.
+}
+
+// Diagnostic is a warning about a driver's laps for officials to check.
+type Diagnostic struct {
+	RaceNumber string
+	Lap        uint // Lap number shown on the Natsoft page, zero when the diagnostic isn't about a single lap.
+	Message    string
+}
+
+// String returns the diagnostic, like: `car 42, lap 23: 2m10.401s is 2.0x the median lap time`.
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Name(), d.Message)
+}
+
+// Name returns the car and lap the diagnostic refers to, the way officials read them off the Natsoft page.
+func (d *Diagnostic) Name() string {
+	if d.Lap == 0 {
+		return fmt.Sprintf("car %s", d.RaceNumber)
+	}
+	return fmt.Sprintf("car %s, lap %d", d.RaceNumber, d.Lap)
+}
+
+// loadAdjustments returns the adjustments listed in the event's adjustments file.
+func loadAdjustments(meta *Meta) (adjustments []Adjustment) {
+	fileName := meta.eventFileName(adjustmentsPrefix)
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+
+	lines := bytes.Split(src, lineDelimiter)
+	for i := range lines {
+		line := string(bytes.TrimSpace(lines[i]))
+		var comment string
+		if c := strings.Index(line, "#"); c >= 0 {
+			line, comment = strings.TrimSpace(line[:c]), strings.TrimSpace(line[c+1:])
+		}
+		if line == "" {
+			continue
+		}
+
+		a, err := parseAdjustment(strings.Fields(line))
+		if err != nil {
+			fmt.Printf("%s line %d: %s\n", fileName, i+1, err)
+			continue
+		}
+		a.Comment = comment
+		adjustments = append(adjustments, a)
+	}
+
+	return adjustments
+}
+
+// parseAdjustment returns an adjustment given the fields: racing number, lap, action, decision.
+func parseAdjustment(fields []string) (a Adjustment, err error) {
+	//nolint:gomnd // Ignore hardcoded numbers
+	if len(fields) != 4 {
+		return a, fmt.Errorf("expected racing number, lap, action and decision, got %q", strings.Join(fields, " "))
+	}
+
+	a.RaceNumber = fields[0]
+	lap, err := strconv.ParseUint(fields[1], 10, 0)
+	if err != nil {
+		return a, fmt.Errorf("invalid lap %q", fields[1])
+	}
+	a.Lap = uint(lap)
+
+	a.Action = strings.ToLower(fields[2])
+	if a.Action != adjustSplit && a.Action != adjustMerge {
+		return a, fmt.Errorf("unknown action %q, expected %s or %s", fields[2], adjustSplit, adjustMerge)
+	}
+
+	a.Decision = strings.ToLower(fields[3])
+	if a.Decision != decisionPending && a.Decision != decisionApprove && a.Decision != decisionReject {
+		return a, fmt.Errorf("unknown decision %q, expected %s, %s or %s", fields[3], decisionPending, decisionApprove, decisionReject)
+	}
+
+	return a, nil
+}
+
+// saveAdjustments appends new suggested adjustments to the event's adjustments file for officials to approve or reject.
+func saveAdjustments(meta *Meta, adjustments []Adjustment) {
+	var buf bytes.Buffer
+	for i := range adjustments {
+		if !adjustments[i].isNew {
+			continue
+		}
+		a := &adjustments[i]
+		fmt.Fprintf(&buf, "%s %d %s %s # %s%s", a.RaceNumber, a.Lap, a.Action, a.Decision, a.Comment, newLine)
+	}
+	if buf.Len() == 0 {
+		return
+	}
+
+	fileName := meta.eventFileName(adjustmentsPrefix)
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil || len(src) == 0 {
+		src = []byte("# Transponder glitch adjustments. Change `pending` to `approve` or `reject` then run again." + newLine +
+			"# Racing number, Natsoft lap number, action, decision." + newLine)
+	} else if !bytes.HasSuffix(src, lineDelimiter) {
+		src = append(src, newLine...)
+	}
+
+	checkErr(ioutil.WriteFile(fileName, append(src, buf.Bytes()...), filePermission))
+	fmt.Println("Suggested lap adjustments saved to", fileName)
+}
+
+// glitches returns adjustments suggested for laps about double or half of the driver's median lap time.
+func (driver *Driver) glitches() (suggestions []Adjustment) {
+	median := driver.medianLap()
+	if median == 0 {
+		return nil
+	}
+
+	for i := range driver.Timing {
+		lap := &driver.Timing[i]
+		// Only laps from the timing system can have transponder glitches.
+		if !lap.isCounted() || lap.Source != "" {
+			continue
+		}
+
+		ratio := lap.Time.Seconds() / median.Seconds()
+		//nolint:gomnd // Ignore hardcoded numbers
+		switch {
+		case ratio >= 2*(1-cfg.GlitchTolerance) && ratio <= 2*(1+cfg.GlitchTolerance):
+			suggestions = append(suggestions, Adjustment{
+				RaceNumber: driver.RaceNumber,
+				Lap:        lap.Natsoft,
+				Action:     adjustSplit,
+				Comment:    fmt.Sprintf("%v is %.1fx the median lap time %v, split into 2 laps of %v", lap.Time, ratio, median, halfLap(lap.Time)),
+			})
+		case ratio <= 0.5*(1+cfg.GlitchTolerance) && i+1 < len(driver.Timing) && driver.Timing[i+1].Run == lap.Run && driver.Timing[i+1].isCounted() && driver.Timing[i+1].Source == "":
+			next := &driver.Timing[i+1]
+			sum := lap.Time + next.Time
+			if r := sum.Seconds() / median.Seconds(); r < 1-cfg.GlitchTolerance || r > 1+cfg.GlitchTolerance {
+				continue
+			}
+			suggestions = append(suggestions, Adjustment{
+				RaceNumber: driver.RaceNumber,
+				Lap:        lap.Natsoft,
+				Action:     adjustMerge,
+				Comment:    fmt.Sprintf("%v and %v are %.1fx the median lap time %v, merge into 1 lap of %v", lap.Time, next.Time, ratio, median, sum),
+			})
+		}
+	}
+
+	return suggestions
+}
+
+// medianLap returns the median of the driver's laps counted, including Qualifying.
+func (driver *Driver) medianLap() time.Duration {
+	var seconds []float64
+	for i := range driver.Timing {
+		if driver.Timing[i].isCounted() {
+			seconds = append(seconds, driver.Timing[i].Time.Seconds())
+		}
+	}
+	if len(seconds) == 0 {
+		return 0
+	}
+
+	return secondsToDuration(median(seconds))
+}
+
+// adjust applies approved adjustments to the driver's laps and returns a diagnostic for each glitch detected.
+// New suggestions are appended to adjustments with a pending decision.
+func (driver *Driver) adjust(adjustments *[]Adjustment) (diagnostics []Diagnostic) {
+	suggestions := driver.glitches()
+
+	for s := range suggestions {
+		a := findAdjustment(*adjustments, &suggestions[s])
+		if a == nil {
+			suggestions[s].Decision = decisionPending
+			suggestions[s].isNew = true
+			*adjustments = append(*adjustments, suggestions[s])
+			a = &suggestions[s]
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			RaceNumber: driver.RaceNumber,
+			Lap:        a.Lap,
+			Message:    fmt.Sprintf("%s (%s %s)", suggestions[s].Comment, a.Action, a.Decision),
+		})
+	}
+
+	// Apply approved adjustments, including those no longer detected as glitches.
+	var approved []*Adjustment
+	for i := range *adjustments {
+		a := &(*adjustments)[i]
+		if a.Decision == decisionApprove && strings.EqualFold(a.RaceNumber, driver.RaceNumber) {
+			approved = append(approved, a)
+		}
+	}
+	// Apply the last lap first, because the laps following each adjustment are renumbered.
+	sort.SliceStable(approved, func(i, j int) bool {
+		return approved[i].Lap > approved[j].Lap
+	})
+	var changed bool
+	for _, a := range approved {
+		if driver.applyAdjustment(a) {
+			changed = true
+		}
+	}
+	if changed {
+		driver.score()
+	}
+
+	return diagnostics
+}
+
+// findAdjustment returns the adjustment for the same lap and action as suggestion, or nil if not found.
+func findAdjustment(adjustments []Adjustment, suggestion *Adjustment) *Adjustment {
+	for i := range adjustments {
+		a := &adjustments[i]
+		if strings.EqualFold(a.RaceNumber, suggestion.RaceNumber) && a.Lap == suggestion.Lap && a.Action == suggestion.Action {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// applyAdjustment corrects the lap referred to by the adjustment and returns true if the lap was found.
+// The laps that follow are renumbered, so each lap number is still only used once.
+func (driver *Driver) applyAdjustment(a *Adjustment) bool {
+	for i := range driver.Timing {
+		lap := driver.Timing[i]
+		if lap.Natsoft != a.Lap || !lap.isCounted() {
+			continue
+		}
+
+		switch a.Action {
+		case adjustSplit:
+			lap.Time = halfLap(lap.Time)
+			lap.Note = adjustSplit
+			driver.Timing[i] = lap
+			driver.Timing = append(driver.Timing[:i+1], append([]Lap{lap}, driver.Timing[i+1:]...)...)
+			driver.renumber(i+1, 1)
+			return true
+		case adjustMerge:
+			if i+1 >= len(driver.Timing) || driver.Timing[i+1].Run != lap.Run {
+				return false
+			}
+			lap.Time += driver.Timing[i+1].Time
+			lap.Note = adjustMerge
+			driver.Timing[i] = lap
+			driver.Timing = append(driver.Timing[:i+1], driver.Timing[i+2:]...)
+			driver.renumber(i+1, -1)
+			return true
+		}
+	}
+
+	fmt.Printf("Unable to %s car %s, lap %d\n", a.Action, a.RaceNumber, a.Lap)
+	return false
+}
+
+// renumber adds change to the lap numbers of the laps from index first onwards.
+// Lap numbers within a run only change in the same run as the lap before first.
+func (driver *Driver) renumber(first, change int) {
+	if first < 1 {
+		return
+	}
+
+	run := driver.Timing[first-1].Run
+	for i := first; i < len(driver.Timing); i++ {
+		lap := &driver.Timing[i]
+		if lap.Natsoft >= 1 {
+			lap.Natsoft = uint(int(lap.Natsoft) + change)
+		}
+		if lap.Run == run {
+			lap.Number = uint(int(lap.Number) + change)
+		}
+	}
+}
+
+// halfLap returns half the lap time, rounded to the same precision as Natsoft lap times.
+func halfLap(lapTime time.Duration) time.Duration {
+	//nolint:gomnd // Ignore hardcoded numbers
+	return (lapTime / 2).Round(lapPrecision)
+}
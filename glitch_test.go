@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAdjustment(t *testing.T) {
+	tests := []struct {
+		line    []string
+		want    Adjustment
+		isError bool
+	}{
+		{line: []string{"42", "7", "split", "pending"}, want: Adjustment{RaceNumber: "42", Lap: 7, Action: adjustSplit, Decision: decisionPending}},
+		{line: []string{"7A", "12", "Merge", "APPROVE"}, want: Adjustment{RaceNumber: "7A", Lap: 12, Action: adjustMerge, Decision: decisionApprove}},
+		{line: []string{"42", "7", "split", "reject"}, want: Adjustment{RaceNumber: "42", Lap: 7, Action: adjustSplit, Decision: decisionReject}},
+		{line: []string{"42", "7", "split"}, isError: true},
+		{line: []string{"42", "7", "split", "approve", "now"}, isError: true},
+		{line: []string{"42", "seven", "split", "approve"}, isError: true},
+		{line: []string{"42", "-1", "split", "approve"}, isError: true},
+		{line: []string{"42", "7", "delete", "approve"}, isError: true},
+		{line: []string{"42", "7", "split", "maybe"}, isError: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseAdjustment(test.line)
+		if (err != nil) != test.isError {
+			t.Errorf("parseAdjustment(%q) error = %v, want error %v", test.line, err, test.isError)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("parseAdjustment(%q) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestGlitches(t *testing.T) {
+	defer func(tolerance float64) { cfg.GlitchTolerance = tolerance }(cfg.GlitchTolerance)
+	cfg.GlitchTolerance = 0.2
+
+	driver := Driver{RaceNumber: "42", Timing: natsoftLaps()}
+
+	var got []string
+	for _, a := range driver.glitches() {
+		got = append(got, fmt.Sprintf("%s %d %s", a.RaceNumber, a.Lap, a.Action))
+	}
+	if want := []string{"42 3 split", "42 7 merge"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("glitches() = %q, want %q", got, want)
+	}
+}
+
+// natsoftLaps returns laps where Natsoft lap numbers 1 to 4 are Qualifying, 5 separates the runs, then 6 to 10 are run 1.
+func natsoftLaps() []Lap {
+	laps := append(timing(0, 66, 65, 130, 66), timing(1, 67, 33, 34, 66, 67)...)
+	for i := range laps {
+		laps[i].Natsoft = uint(i) + 1
+		if laps[i].Run == 1 {
+			laps[i].Natsoft++
+		}
+	}
+
+	return laps
+}
+
+func TestAdjust(t *testing.T) {
+	adjustments := []Adjustment{
+		{RaceNumber: "42", Lap: 3, Action: adjustSplit, Decision: decisionApprove},
+		{RaceNumber: "42", Lap: 7, Action: adjustMerge, Decision: decisionApprove},
+		{RaceNumber: "42", Lap: 9, Action: adjustMerge, Decision: decisionReject},
+		{RaceNumber: "7", Lap: 6, Action: adjustSplit, Decision: decisionApprove},
+	}
+
+	driver := Driver{RaceNumber: "42", Timing: natsoftLaps()}
+	driver.adjust(&adjustments)
+
+	type lap struct {
+		Run, Number, Natsoft uint
+		Time                 time.Duration
+		Note                 string
+	}
+	want := []lap{
+		{0, 1, 1, 66 * time.Second, ""},
+		{0, 2, 2, 65 * time.Second, ""},
+		{0, 3, 3, 65 * time.Second, adjustSplit},
+		{0, 4, 4, 65 * time.Second, adjustSplit},
+		{0, 5, 5, 66 * time.Second, ""},
+		{1, 1, 7, 67 * time.Second, ""},
+		{1, 2, 8, 67 * time.Second, adjustMerge},
+		{1, 3, 9, 66 * time.Second, ""},
+		{1, 4, 10, 67 * time.Second, ""},
+	}
+	var got []lap
+	for _, l := range driver.Timing {
+		got = append(got, lap{l.Run, l.Number, l.Natsoft, l.Time, l.Note})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got laps\n%v\nwant\n%v", got, want)
+	}
+	if driver.Qualify != 65*time.Second || driver.Laps != 4 {
+		t.Errorf("got Qualify %s and %d laps, want 1m5s and 4 laps", driver.Qualify, driver.Laps)
+	}
+}
+
+func TestEventFileName(t *testing.T) {
+	tests := []struct {
+		meta Meta
+		want string
+	}{
+		{Meta{Title: "Sports Car Track Day", Date: time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)}, "adjustments-2023-03-12 Sports Car Track Day.txt"},
+		{Meta{Title: "Winton 24/7?", Date: time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)}, "adjustments-2023-03-12 Winton 24 7.txt"},
+		{Meta{Date: time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)}, "adjustments-2023-03-12.txt"},
+	}
+
+	for _, test := range tests {
+		if got := test.meta.eventFileName(adjustmentsPrefix); got != test.want {
+			t.Errorf("eventFileName(%q) = %q, want %q", test.meta.Title, got, test.want)
+		}
+	}
+}
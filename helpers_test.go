@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// timing returns a lap for each lap time in seconds during run, counted towards the results.
+func timing(run uint, seconds ...float64) (laps []Lap) {
+	status := lapCounted
+	if run == 0 {
+		status = lapQualifying
+	}
+	for i, s := range seconds {
+		laps = append(laps, Lap{Run: run, Number: uint(i) + 1, Natsoft: uint(i) + 1, Time: secondsToDuration(s), Status: status})
+	}
+
+	return laps
+}
+
+// captureStdout returns everything printed to standard output while calling f.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	f()
+	checkErr(w.Close())
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
@@ -7,9 +7,17 @@ import (
 	"strings"
 )
 
-func htmlHeading(eventName string, driversQty uint) *bytes.Buffer {
-	return bytes.NewBufferString(
-		fmt.Sprintf(`<!DOCTYPE html><html lang=en><title>%s</title><link rel=icon href="%s"><style>body{font-family:sans-serif}h1{color:#07f;text-align:center}table{width:100%%}th{text-align:left}</style><h1><img src="%[16]s" alt="%[17]s logo"> %[1]s</h1><b>%[3]s %d</b><table><thead><tr><th>%[5]s<th>%[6]s<th>%[7]s<th>%[8]s<th>%[9]s<th>%[10]s<th>%[9]s<th>%[11]s<th>%[9]s<th>%[12]s<th>%[13]s<th>%[14]s<th>%[15]s<tbody>`,
+func htmlHeading(eventName string, meta *Meta, driversQty uint, sources bool) *bytes.Buffer {
+	var details string
+	if subtitle := meta.Subtitle(); subtitle != "" {
+		details = "<p>" + subtitle
+	}
+	if len(meta.Sessions) >= 1 {
+		details += fmt.Sprintf("<p>%s %s", hSessions, strings.Join(meta.Sessions, ", "))
+	}
+
+	html := bytes.NewBufferString(
+		fmt.Sprintf(`<!DOCTYPE html><html lang=en><title>%s</title><link rel=icon href="%s"><style>body{font-family:sans-serif}h1{color:#07f;text-align:center}table{width:100%%}th{text-align:left}</style><h1><img src="%[14]s" alt="%[15]s logo"> %[1]s</h1>%[16]s<b>%[3]s %d</b><table><thead><tr><th>%[5]s<th>%[6]s<th>%[7]s<th>%[8]s<th>%[9]s<th>%[10]s<th>%[9]s<th>%[11]s<th>%[9]s<th>%[12]s<th>%[13]s`,
 			eventName,
 			faviconB64,
 			hCompetitors,
@@ -23,16 +31,40 @@ func htmlHeading(eventName string, driversQty uint) *bytes.Buffer {
 			hSlowest,
 			hAverage,
 			hPercentage,
-			hRuns,
-			hLaps,
 			logoB64,
 			championship,
+			details,
 		),
 	)
+
+	if cfg.hasHandicaps() {
+		_, err := fmt.Fprintf(html, "<th>%s<th>%s<th>%s", hCar, hHandicap, hScore)
+		checkErr(err)
+	}
+	if cfg.Consistency {
+		_, err := fmt.Fprintf(html, "<th>%s<th>%s<th>%s<th>%s", hMean, hMedian, hStdDev, hCV)
+		checkErr(err)
+	}
+	if cfg.Progression {
+		_, err := fmt.Fprintf(html, "<th>%s", hChange)
+		checkErr(err)
+	}
+
+	_, err := fmt.Fprintf(html, "<th>%s<th>%s", hRuns, hLaps)
+	checkErr(err)
+	if sources {
+		_, err = fmt.Fprintf(html, "<th>%s", hSource)
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(html, "<tbody>")
+	checkErr(err)
+
+	return html
 }
 
-func htmlRow(html io.Writer, d *Driver, ordinal string) {
-	_, err := fmt.Fprintf(html, "<tr><td>%s<td>%s<td>%s<td>%v<td>%.4f<td>%v<td>%.4f<td>%v<td>%.4f<td>%.5f<td>%.8f<td>%d<td>%d",
+// htmlRow adds the driver's results, with the sources of any laps not from the timing system when sources is true.
+func htmlRow(html io.Writer, d *Driver, ordinal string, sources bool) {
+	_, err := fmt.Fprintf(html, "<tr><td>%s<td>%s<td>%s<td>%v<td>%.4f<td>%v<td>%.4f<td>%v<td>%.4f<td>%.5f<td>%.8f",
 		ordinal,
 		d.RaceNumber,
 		d.Name,
@@ -41,18 +73,165 @@ func htmlRow(html io.Writer, d *Driver, ordinal string) {
 		d.Slowest, d.Slowest.Seconds(),
 		d.SlowAv,
 		d.Percentage,
-		d.Runs,
-		d.Laps,
 	)
 	checkErr(err)
+
+	if cfg.hasHandicaps() {
+		_, err = fmt.Fprintf(html, "<td>%s<td>%.4f<td>%.8f", d.Car, d.Handicap, d.Score)
+		checkErr(err)
+	}
+	if cfg.Consistency {
+		_, err = fmt.Fprintf(html, "<td>%.4f<td>%.4f<td>%.4f<td>%.2f", d.Mean, d.Median, d.StdDev, d.CV)
+		checkErr(err)
+	}
+	if cfg.Progression {
+		_, err = fmt.Fprintf(html, "<td>%s", d.positionChange())
+		checkErr(err)
+	}
+
+	_, err = fmt.Fprintf(html, "<td>%d<td>%d", d.Runs, d.Laps)
+	checkErr(err)
+	if sources {
+		_, err = fmt.Fprintf(html, "<td>%s", d.Sources)
+		checkErr(err)
+	}
 }
 
-func htmlFooter(html *bytes.Buffer, missingCars []string) {
+func htmlNotClassified(html io.Writer, drivers []Driver) {
 	_, err := fmt.Fprint(html, "</table>")
 	checkErr(err)
 
-	if len(missingCars) >= 1 {
-		_, err = fmt.Fprintf(html, "<h3>%s</h3><ul><li>%s</ul>", hMissing, strings.Join(missingCars, "<li>"))
+	htmlReasons(html, hNotClassified, drivers)
+}
+
+// htmlReasons lists the drivers excluded from the results under heading, with the reason why.
+func htmlReasons(html io.Writer, heading string, drivers []Driver) {
+	if len(drivers) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(html, "<h3>%s</h3><table><thead><tr><th>%s<th>%s<th>%s<tbody>", heading, hRacingNumber, hDriver, hReason)
+	checkErr(err)
+	for i := range drivers {
+		_, err = fmt.Fprintf(html, "<tr><td>%s<td>%s<td>%s", drivers[i].RaceNumber, drivers[i].Name, drivers[i].Reason)
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(html, "</table>")
+	checkErr(err)
+}
+
+func htmlRuns(html io.Writer, drivers []Driver) {
+	_, err := fmt.Fprintf(html, "<h3>%s</h3><table><thead><tr><th>%s<th>%s<th>%s<th>%s<th>%s<th>%s<th>%s<th>%s<tbody>", hRunStats, hRacingNumber, hDriver, hRun, hLaps, hFastest, hSlowest, hAverageLap, hSource)
+	checkErr(err)
+
+	for i := range drivers {
+		for r := range drivers[i].RunStats {
+			if !drivers[i].drove(uint(r)) {
+				continue
+			}
+			run := &drivers[i].RunStats[r]
+			_, err = fmt.Fprintf(html, "<tr><td>%s<td>%s<td>%s<td>%d<td>%v<td>%v<td>%v<td>%s",
+				drivers[i].RaceNumber,
+				drivers[i].Name,
+				runName(r),
+				run.Laps,
+				run.Fastest,
+				run.Slowest,
+				run.Average(),
+				run.Source,
+			)
+			checkErr(err)
+		}
+	}
+
+	_, err = fmt.Fprint(html, "</table>")
+	checkErr(err)
+}
+
+func htmlProgression(html io.Writer, drivers []Driver) {
+	if len(drivers) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(html, "<h3>%s</h3><table><thead><tr><th>%s<th>%s<th>%s", hProgression, hPosition, hRacingNumber, hDriver)
+	checkErr(err)
+	for r := range drivers[0].Progress {
+		_, err = fmt.Fprintf(html, "<th>%s", progressName(r))
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(html, "<tbody>")
+	checkErr(err)
+
+	for i := range drivers {
+		_, err = fmt.Fprintf(html, "<tr><td>%s<td>%s<td>%s", drivers[i].Ordinal(), drivers[i].RaceNumber, drivers[i].Name)
+		checkErr(err)
+		for _, standing := range drivers[i].Progress {
+			_, err = fmt.Fprintf(html, "<td>%s", standing.Ordinal())
+			checkErr(err)
+		}
+	}
+
+	_, err = fmt.Fprint(html, "</table>")
+	checkErr(err)
+}
+
+func htmlAnalysis(html io.Writer, analyses []Analysis) {
+	if len(analyses) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(html, "<h3>%s</h3><table><thead><tr><th>%s<th>%s<th>%s<th>%s<th>%s<th>%s<th>%s<tbody>", hAnalysis, hPosition, hRacingNumber, hDriver, hGapAhead, hGapLeader, hFastestNeeded, hSlowestNeeded)
+	checkErr(err)
+	for i := range analyses {
+		a := &analyses[i]
+		_, err = fmt.Fprintf(html, "<tr><td>%s<td>%s<td>%s<td>%.8f<td>%.8f<td>%s<td>%s",
+			a.Driver.Ordinal(),
+			a.Driver.RaceNumber,
+			a.Driver.Name,
+			a.GapAhead,
+			a.GapLeader,
+			needed(a.Fastest),
+			needed(a.Slowest),
+		)
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(html, "</table>")
+	checkErr(err)
+}
+
+func htmlAwards(html io.Writer, awards []Award) {
+	if len(awards) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(html, "<h3>%s</h3><table>", hAwards)
+	checkErr(err)
+	for i := range awards {
+		_, err = fmt.Fprintf(html, "<tr><th>%s<td>%s", awards[i].Heading, awards[i].String())
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(html, "</table>")
+	checkErr(err)
+}
+
+func htmlDiagnostics(html io.Writer, diagnostics []Diagnostic) {
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(html, "<h3>%s</h3><ul>", hDiagnostics)
+	checkErr(err)
+	for i := range diagnostics {
+		_, err = fmt.Fprintf(html, "<li>%s", diagnostics[i].String())
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(html, "</ul>")
+	checkErr(err)
+}
+
+func htmlFooter(html io.Writer, heading string, cars []string) {
+	if len(cars) >= 1 {
+		_, err := fmt.Fprintf(html, "<h3>%s</h3><ul><li>%s</ul>", heading, strings.Join(cars, "<li>"))
 		checkErr(err)
 	}
 }
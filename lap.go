@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lap statuses.
+const (
+	lapFormation  = "Formation"  // The first lap of each run is skipped, allowing for a grid formation lap.
+	lapQualifying = "Qualifying" // Counted towards the Qualifying lap time only.
+	lapCounted    = "Counted"    // Counted towards the Fastest and Slowest lap times and quantity of laps.
+	lapExcluded   = "Excluded"   // The lap is missing a time.
+)
+
+// Lap sources, where an empty source is the timing system.
+const (
+	sourceManual = "Manual" // Hand timed with a stopwatch.
+	sourceLogger = "Logger" // Recorded by the driver's data logger, a secondary source used when the timing system missed the car.
+)
+
+// Lap is a single lap completed by a driver.
+type Lap struct {
+	Run     uint          // Zero based index, where zero is the Qualifying session.
+	Number  uint          // Lap number within the run, starting from 1.
+	Natsoft uint          // Lap number shown on the Natsoft page, counting laps missing a time.
+	Time    time.Duration //
+	Status  string        // One of lapFormation, lapQualifying, lapCounted or lapExcluded.
+	Note    string        // Adjustment applied to correct a transponder glitch, like adjustSplit or adjustMerge, or why the lap is excluded, like notePitStop.
+	Source  string        // Where the lap time came from when it isn't the timing system, like sourceManual.
+}
+
+// parseLapTime returns the lap time given hours, minutes and seconds like 0:01:05.4321, minutes and seconds like 1:05.4321, or seconds like 65.4321.
+// Decimal commas like 1:05,4321 are also accepted.
+func parseLapTime(s string) (time.Duration, error) {
+	// Convert time format 00:00:00.0000 to 00h00m00.0000s so it can be parsed.
+	parts := strings.Split(decimalPoint(strings.TrimSpace(s)), ":")
+	units := []string{"s", "m", "h"}
+	if len(parts) > len(units) {
+		return 0, fmt.Errorf("invalid lap time %q", s)
+	}
+
+	var d string
+	for i := range parts {
+		d += parts[i] + units[len(parts)-1-i]
+	}
+
+	return time.ParseDuration(d)
+}
+
+// decimalPoint replaces a decimal comma used by timing software and spreadsheets in some regions, like 1:05,432, with a decimal point.
+func decimalPoint(s string) string {
+	if strings.Contains(s, ".") {
+		return s
+	}
+	return strings.Replace(s, ",", ".", 1)
+}
+
+// isCounted returns true if the lap is used to calculate the driver's results.
+func (l *Lap) isCounted() bool {
+	return l.Status == lapQualifying || l.Status == lapCounted
+}
+
+// Run contains the statistics of laps counted during a single run/session.
+type Run struct {
+	Laps    uint
+	Fastest time.Duration
+	Slowest time.Duration
+	Total   time.Duration // Sum of all lap times, used to calculate the Average.
+	Source  string        // Where the lap times came from when it isn't the timing system, like sourceManual.
+}
+
+// Average returns the mean lap time of the run, rounded to the same precision as Natsoft lap times.
+func (r *Run) Average() time.Duration {
+	if r.Laps == 0 {
+		return 0
+	}
+	return (r.Total / time.Duration(r.Laps)).Round(lapPrecision)
+}
+
+// add includes lapTime in the run statistics.
+func (r *Run) add(lapTime time.Duration) {
+	if r.Laps == 0 || lapTime < r.Fastest {
+		r.Fastest = lapTime
+	}
+	if lapTime > r.Slowest {
+		r.Slowest = lapTime
+	}
+	r.Laps++
+	r.Total += lapTime
+}
+
+// runName returns the name of the run/session given its zero based index.
+func runName(run int) string {
+	if run == 0 {
+		return hQualify
+	}
+	return strconv.Itoa(run)
+}
+
+// sessionName returns the name of the run/session used in messages, like "Qualifying" or "run 2".
+func sessionName(run uint) string {
+	if run == 0 {
+		return lapQualifying
+	}
+	return "run " + runName(int(run))
+}
+
+// sessionTitle returns the name of the run used as a session heading, like "Qualifying" or "Run 1".
+func sessionTitle(run uint) string {
+	if run == 0 {
+		return lapQualifying
+	}
+	return "Run " + runName(int(run))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var r Run
+	if r.Average() != 0 {
+		t.Errorf("Average() without any laps = %s, want 0", r.Average())
+	}
+
+	for _, lapTime := range []time.Duration{66 * time.Second, 64500 * time.Millisecond, 65123400 * time.Microsecond} {
+		r.add(lapTime)
+	}
+	want := Run{Laps: 3, Fastest: 64500 * time.Millisecond, Slowest: 66 * time.Second, Total: 195623400 * time.Microsecond}
+	if r != want {
+		t.Errorf("got %+v, want %+v", r, want)
+	}
+	if got := r.Average(); got != 65207800*time.Microsecond {
+		t.Errorf("Average() = %s, want 1m5.2078s", got)
+	}
+}
+
+func TestParseLapTime(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		isError bool
+	}{
+		{s: "1:05.4321", want: 65432100 * time.Microsecond},
+		{s: " 0:01:05.4321 ", want: 65432100 * time.Microsecond},
+		{s: "65.4321", want: 65432100 * time.Microsecond},
+		{s: "1:05,432", want: 65432 * time.Millisecond},
+		{s: "65,432", want: 65432 * time.Millisecond},
+		{s: "2:10", want: 130 * time.Second},
+		{s: "1,05.4", isError: true},
+		{s: "-:--.----", isError: true},
+		{s: "In Pit", isError: true},
+		{s: "1:1:1:05.4", isError: true},
+		{s: "", isError: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseLapTime(test.s)
+		if (err != nil) != test.isError {
+			t.Errorf("parseLapTime(%q) error = %v, want error %v", test.s, err, test.isError)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("parseLapTime(%q) = %s, want %s", test.s, got, test.want)
+		}
+	}
+}
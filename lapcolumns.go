@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lap time formats used in lap time spreadsheets, see LapColumns.
+const (
+	timeAuto         = "auto"         // Hours, minutes and seconds like 1:05.432, or seconds like 65.432.
+	timeSeconds      = "seconds"      // Seconds like 65.432.
+	timeMilliseconds = "milliseconds" // Milliseconds like 65432.
+)
+
+// LapColumns contains the column headings of lap time spreadsheets saved as CSV or TSV files.
+// Headings are matched case-insensitively. Leave a heading empty when the spreadsheet doesn't contain it.
+type LapColumns struct {
+	RaceNumber string `json:"raceNumber"` // Required.
+	Driver     string `json:"driver"`
+	Run        string `json:"run"`        // Run or session of each lap. When empty, each file contains a single run.
+	Lap        string `json:"lap"`        // Lap number within the run, used to order the laps. When empty, laps are listed in order.
+	LapTime    string `json:"lapTime"`    // Required.
+	TimeFormat string `json:"timeFormat"` // timeAuto, timeSeconds or timeMilliseconds.
+}
+
+// parseLapColumns returns the laps and session headings in a lap time spreadsheet, using the headings in the `lapColumns` setting.
+// Runs written like Q or 1 keep their run number, otherwise runs are numbered from run, and the lap times written in cfg.LapColumns.TimeFormat.
+func parseLapColumns(src []byte, run uint) (laps []timedLap, sessions []string, err error) {
+	r := newCSVReader(src)
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &cfg.LapColumns
+	number, driver, runs, lap, lapTime := lapColumnIndex(header, c.RaceNumber), lapColumnIndex(header, c.Driver), lapColumnIndex(header, c.Run), lapColumnIndex(header, c.Lap), lapColumnIndex(header, c.LapTime)
+	if number < 0 || lapTime < 0 {
+		return nil, nil, fmt.Errorf("expected columns %q and %q, please check the `lapColumns` setting", c.RaceNumber, c.LapTime)
+	}
+	switch c.TimeFormat {
+	case "", timeAuto, timeSeconds, timeMilliseconds:
+	default:
+		return nil, nil, fmt.Errorf("unknown time format %q, expected %s, %s or %s", c.TimeFormat, timeAuto, timeSeconds, timeMilliseconds)
+	}
+
+	var names []string // Value in the run column of each lap.
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return laps, nil, err
+		}
+
+		t := timedLap{
+			RaceNumber: column(record, number),
+			Name:       column(record, driver),
+			Run:        run,
+		}
+		if t.RaceNumber == "" {
+			continue
+		}
+		if n, err := strconv.ParseUint(column(record, lap), 10, 0); err == nil {
+			t.Number = uint(n)
+		}
+		// Laps without a valid time are excluded.
+		t.Time, _ = parseColumnTime(column(record, lapTime), c.TimeFormat) //nolint:errcheck // Excluded when invalid.
+
+		laps = append(laps, t)
+		names = append(names, column(record, runs))
+	}
+
+	if runs < 0 {
+		return laps, nil, nil
+	}
+
+	// Use the run numbers when every run is written like Q or 1, otherwise number the runs from run in order of first appearance.
+	numbers, ok := runNumbers(names)
+	if ok {
+		if first := minRun(numbers); first < run {
+			return nil, nil, fmt.Errorf("contains %s, which is already imported from an earlier file", sessionName(first))
+		}
+		for n := run; n <= maxRun(numbers); n++ {
+			sessions = append(sessions, sessionTitle(n))
+		}
+		for i := range laps {
+			laps[i].Run = numbers[names[i]]
+		}
+		return laps, sessions, nil
+	}
+
+	numbers = make(map[string]uint)
+	for i := range laps {
+		if _, found := numbers[names[i]]; !found {
+			numbers[names[i]] = uint(len(sessions))
+			sessions = append(sessions, names[i])
+		}
+		laps[i].Run = run + numbers[names[i]]
+	}
+
+	return laps, sessions, nil
+}
+
+// isLapColumns returns true if the first line of the CSV file contains the racing number and lap time columns in the `lapColumns` setting.
+func isLapColumns(src []byte) bool {
+	header, err := newCSVReader(src).Read()
+	return err == nil && lapColumnIndex(header, cfg.LapColumns.RaceNumber) >= 0 && lapColumnIndex(header, cfg.LapColumns.LapTime) >= 0
+}
+
+// lapColumnIndex returns the index of the column in header named heading, or -1 when heading is empty or not found.
+func lapColumnIndex(header []string, heading string) int {
+	if heading == "" {
+		return -1
+	}
+
+	return columnIndex(header, heading)
+}
+
+// parseColumnTime returns the lap time written in format.
+func parseColumnTime(s, format string) (time.Duration, error) {
+	switch format {
+	case timeAuto, "":
+		return parseLapTime(s)
+	case timeSeconds:
+		seconds, err := strconv.ParseFloat(decimalPoint(strings.TrimSpace(s)), 64)
+		return secondsToDuration(seconds), err
+	case timeMilliseconds:
+		ms, err := strconv.ParseFloat(decimalPoint(strings.TrimSpace(s)), 64)
+		return time.Duration(ms * float64(time.Millisecond)), err
+	}
+
+	return 0, fmt.Errorf("unknown time format %q", format)
+}
+
+// runNumbers returns the run of each name, when every name is Q, Qualifying or ends with a run number like "Run 1".
+func runNumbers(names []string) (numbers map[string]uint, ok bool) {
+	numbers = make(map[string]uint)
+	for _, name := range names {
+		if _, ok := numbers[name]; ok {
+			continue
+		}
+
+		fields := strings.Fields(name)
+		switch {
+		case len(fields) == 0:
+			return nil, false
+		case strings.EqualFold(name, runQualify) || strings.EqualFold(name, "Qualify") || strings.EqualFold(name, "Qualifying"):
+			numbers[name] = 0
+		default:
+			n, err := strconv.ParseUint(fields[len(fields)-1], 10, 0)
+			if err != nil {
+				return nil, false
+			}
+			numbers[name] = uint(n)
+		}
+	}
+
+	return numbers, true
+}
+
+// maxRun returns the last run numbered.
+func maxRun(numbers map[string]uint) (last uint) {
+	for _, n := range numbers {
+		if n > last {
+			last = n
+		}
+	}
+
+	return last
+}
+
+// minRun returns the first run numbered.
+func minRun(numbers map[string]uint) (first uint) {
+	first = ^uint(0)
+	for _, n := range numbers {
+		if n < first {
+			first = n
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLapColumns(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.LapColumns = LapColumns{RaceNumber: "Car", Driver: "Name", Run: "Session", Lap: "Lap", LapTime: "Time", TimeFormat: timeAuto}
+
+	type lap struct {
+		RaceNumber string
+		Run        uint
+		Time       time.Duration
+	}
+	tests := []struct {
+		src      string
+		run      uint
+		format   string
+		laps     []lap
+		sessions []string
+		isError  bool
+	}{
+		{
+			src:      "Car,Name,Session,Lap,Time\n42,Jack Black,Q,1,1:05.432\n42,Jack Black,Run 1,1,1:12.5\n7,Ann Lee,Run 2,1,\"66,5\"\n",
+			laps:     []lap{{"42", 0, 65432 * time.Millisecond}, {"42", 1, 72500 * time.Millisecond}, {"7", 2, 66500 * time.Millisecond}},
+			sessions: []string{lapQualifying, "Run 1", "Run 2"},
+		},
+		{
+			// Run numbers in a later file are the event's run numbers.
+			src:      "Car;Session;Time\n42;Run 3;65.4\n42;Run 2;66.1\n",
+			run:      2,
+			laps:     []lap{{"42", 3, 65400 * time.Millisecond}, {"42", 2, 66100 * time.Millisecond}},
+			sessions: []string{"Run 2", "Run 3"},
+		},
+		{
+			src:     "Car,Session,Time\n42,Q,65.4\n42,Run 1,66.1\n",
+			run:     1,
+			isError: true, // Qualifying was already imported.
+		},
+		{
+			// Sessions without run numbers are runs in order of first appearance.
+			src:      "Car,Session,Time\n42,Morning,65.4\n42,Afternoon,66.1\n7,Morning,64.9\n",
+			run:      1,
+			laps:     []lap{{"42", 1, 65400 * time.Millisecond}, {"42", 2, 66100 * time.Millisecond}, {"7", 1, 64900 * time.Millisecond}},
+			sessions: []string{"Morning", "Afternoon"},
+		},
+		{
+			src:    "Car\tTime\n42\t65432\n7\tDNF\n",
+			run:    4,
+			format: timeMilliseconds,
+			laps:   []lap{{"42", 4, 65432 * time.Millisecond}, {"7", 4, 0}},
+		},
+		{src: "Number,Lap Time\n42,65.4\n", isError: true},
+		{src: "Car,Time\n42,65.4\n", format: "minutes", isError: true},
+	}
+
+	for _, test := range tests {
+		cfg.LapColumns.TimeFormat = test.format
+		laps, sessions, err := parseLapColumns([]byte(test.src), test.run)
+		if (err != nil) != test.isError {
+			t.Errorf("parseLapColumns(%q) error = %v, want error %v", test.src, err, test.isError)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		var got []lap
+		for _, l := range laps {
+			got = append(got, lap{l.RaceNumber, l.Run, l.Time})
+		}
+		if !reflect.DeepEqual(got, test.laps) || !reflect.DeepEqual(sessions, test.sessions) {
+			t.Errorf("parseLapColumns(%q) = %v %q, want %v %q", test.src, got, sessions, test.laps, test.sessions)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		fileName, src, want string
+	}{
+		{"session.json", `{"rows":[]}`, formatSpeedhive},
+		{"event.txt", natsoftResults, formatNatsoft},
+		{"passings.csv", "Transponder,Time\n1234567,10:01:02.345\n", formatPassings},
+		{"passings.csv", "1234567,10:01:02.345\n", formatPassings},
+		// The `lapColumns` headings are checked before passings.
+		{"laps.csv", "Race Number,Driver,Transponder,Lap Time\n42,Jack Black,1234567,1:05.432\n", formatColumns},
+		{"orbits.csv", "No.,Name,Lap,Lap Time\n42,Jack Black,1,1:05.432\n", formatOrbits},
+		{"orbits.csv", "No.;First Name;Last Name;Lap;Lap Time\n42;Jack;Black;1;1:05,432\n", formatOrbits},
+		// Files not matching any format are reported instead of being read as Orbits exports.
+		{"notes.txt", "Bring a spare helmet\n", ""},
+		{"laps.csv", "Name,Best\nJack Black,1:05.432\n", ""},
+		{"empty.csv", "", ""},
+	}
+
+	for _, test := range tests {
+		got, err := detectFormat(test.fileName, []byte(test.src))
+		if got != test.want || (err != nil) != (test.want == "") {
+			t.Errorf("detectFormat(%q, %q) = %q, %v, want %q", test.fileName, test.src, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Column headings used by RaceChrono and AiM lap summary CSV exports.
+var (
+	loggerLap     = []string{"Lap", "Lap #", "Lap No.", "Lap Number", "Lap Nr"}
+	loggerLapTime = []string{"Lap Time", "Laptime", "Lap Time (s)", "Time"}
+	loggerStart   = []string{"Start Time", "Lap Start", "Start", "Timestamp", "Time of Day", "Date/Time"}
+
+	// Details listed above the column headings, like `"Time","10:02:15"`, used when the laps don't include their start time.
+	loggerSessionStart = []string{"Session Start", "Start Time", "Time"}
+)
+
+// loggerTolerance is how far apart the timing system's and the data logger's time of day can be for the same lap.
+const loggerTolerance = 2 * time.Second
+
+// loggerFile is a data logger's lap summary CSV supplied by a driver, used as backup timing for their car.
+type loggerFile struct {
+	RaceNumber string
+	FileName   string
+}
+
+// loggedLap is a single lap recorded by a data logger.
+type loggedLap struct {
+	Start time.Duration // Time of day the lap started.
+	Time  time.Duration
+}
+
+// parseLoggerFiles appends the racing number and file name pairs listed in s, like `42=racechrono.csv,512=aim.csv`.
+func parseLoggerFiles(s string, files *[]loggerFile) error {
+	for _, pair := range strings.Split(s, ",") {
+		raceNumber, fileName, ok := strings.Cut(pair, "=")
+		raceNumber, fileName = strings.TrimSpace(raceNumber), strings.TrimSpace(fileName)
+		if !ok || raceNumber == "" || fileName == "" {
+			return fmt.Errorf("expected racing number=file name, got %q", pair)
+		}
+		*files = append(*files, loggerFile{RaceNumber: raceNumber, FileName: fileName})
+	}
+
+	return nil
+}
+
+// loadLoggers returns the laps recorded by each car's data logger, grouped into the event's runs by the time of day each lap started.
+// Like hand timed runs, the first lap of each run after Qualifying is the formation lap.
+// Runs already timed only have their missing laps filled, see Driver.fillGaps.
+func loadLoggers(files []loggerFile, meta *Meta) (runs []ManualRun) {
+	if len(files) >= 1 && len(meta.Starts) == 0 {
+		fmt.Println("Ignoring the data logger laps because the start time of each run is unknown. Natsoft results don't include the time of day, so set when each run started with the schedule setting, like -schedule 09:00,10:30,13:00")
+		return nil
+	}
+
+	for _, f := range files {
+		src, err := ioutil.ReadFile(f.FileName)
+		if err != nil {
+			fmt.Println("Unable to read data logger laps", err)
+			continue
+		}
+
+		laps, err := parseLogger(src)
+		if err != nil {
+			fmt.Println(f.FileName, err)
+			continue
+		}
+
+		var early int
+		first := len(runs)
+		for _, lap := range laps {
+			run, ok := meta.runAt(lap.Start)
+			if !ok {
+				early++
+				continue
+			}
+
+			// Start a new run when the lap is the logger's first lap during the run.
+			if len(runs) == first || runs[len(runs)-1].Run != run {
+				runs = append(runs, ManualRun{RaceNumber: f.RaceNumber, Run: run, Source: sourceLogger})
+			}
+			// Laps without a valid time are kept, so an incomplete formation lap isn't mistaken for the first lap counted.
+			runs[len(runs)-1].Times = append(runs[len(runs)-1].Times, lap.Time)
+			runs[len(runs)-1].Starts = append(runs[len(runs)-1].Starts, lap.Start)
+		}
+
+		if early >= 1 {
+			fmt.Printf("Ignoring %d data logger laps from %s before Qualifying started%s", early, f.FileName, newLine)
+		}
+		fmt.Printf("Using the data logger laps in %s for car %s%s", f.FileName, f.RaceNumber, newLine)
+	}
+
+	return runs
+}
+
+// parseLogger returns the laps listed in a RaceChrono or AiM lap summary CSV export.
+// When the laps don't include their start time, each lap starts when the previous lap finished, from the session start time listed above the column headings.
+func parseLogger(src []byte) (laps []loggedLap, err error) {
+	r := newCSVReader(src)
+	lapTime, start := -1, -1
+	var clock time.Duration // Start time of the next lap.
+	var hasClock bool
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return laps, err
+		}
+
+		// Read any session details above the column headings.
+		if lapTime < 0 {
+			if columnIndex(record, loggerLap...) >= 0 && columnIndex(record, loggerLapTime...) >= 0 {
+				lapTime, start = columnIndex(record, loggerLapTime...), columnIndex(record, loggerStart...)
+			} else if len(record) >= 2 && columnIndex(record[:1], loggerSessionStart...) == 0 {
+				if c, err := parseClock(record[1]); err == nil {
+					clock, hasClock = c, true
+				}
+			}
+			continue
+		}
+
+		lap := loggedLap{Start: clock}
+		// Laps without a valid time, like an incomplete in lap, are skipped.
+		lap.Time, _ = parseLapTime(column(record, lapTime)) //nolint:errcheck // Skipped when invalid.
+		if start >= 0 {
+			if lap.Start, err = parseClock(column(record, start)); err != nil {
+				return laps, err
+			}
+		} else if !hasClock {
+			return nil, fmt.Errorf("expected column %q or the session start time", loggerStart[0])
+		}
+
+		clock = lap.Start + lap.Time
+		laps = append(laps, lap)
+	}
+
+	if lapTime < 0 {
+		return nil, fmt.Errorf("expected columns %q and %q in the data logger export", loggerLap[0], loggerLapTime[0])
+	}
+
+	return laps, nil
+}
+
+// fillGaps fills the driver's laps missing from the timing system during the data logger run, returning the quantity of laps filled.
+// A lap is missing when it doesn't have a time or is skipped in the Natsoft lap numbers, between laps timed during the run.
+// The timing system's laps are given a time of day by adding up their lap times from the data logger lap matching the run's first lap time,
+// and each missing lap is filled with the data logger lap that started at the same time of day.
+func (driver *Driver) fillGaps(m *ManualRun) (filled int) {
+	first, last := -1, -1 // Indexes of the run's laps in driver.Timing.
+	for i := range driver.Timing {
+		if driver.Timing[i].Run != m.Run {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if first < 0 || len(m.Starts) != len(m.Times) {
+		return 0
+	}
+	run := driver.Timing[first : last+1]
+
+	// The data logger may record an out lap before the run's first lap crossing the line.
+	clock, ok := time.Duration(0), false
+	for l := range m.Times {
+		if run[0].Time > 0 && m.Times[l] > 0 && absDuration(m.Times[l]-run[0].Time) < loggerTolerance {
+			clock, ok = m.Starts[l], true
+			break
+		}
+	}
+	if !ok {
+		return 0
+	}
+
+	// Laps after the last lap timed aren't gaps, like the lap Natsoft lists as missing between runs.
+	end := len(run) - 1
+	for end >= 0 && run[end].Time <= 0 {
+		end--
+	}
+
+	laps := make([]Lap, 0, len(run))
+	for i := range run {
+		lap := run[i]
+		if i >= 1 && i <= end {
+			// Insert laps skipped in the Natsoft lap numbers.
+			for n := laps[len(laps)-1].Natsoft + 1; ok && lap.Natsoft >= 1 && n < lap.Natsoft; n++ {
+				var logged Lap
+				if logged, clock, ok = m.lapAt(clock); ok {
+					logged.Natsoft = n
+					laps = append(laps, logged)
+					filled++
+				}
+			}
+
+			if lap.Time <= 0 && ok {
+				var logged Lap
+				if logged, clock, ok = m.lapAt(clock); ok {
+					logged.Natsoft = lap.Natsoft
+					lap = logged
+					filled++
+				}
+				laps = append(laps, lap)
+				continue
+			}
+		}
+
+		// Resynchronise with the data logger's clock to avoid drift.
+		if ok {
+			if l := m.lapStarting(clock); l >= 0 {
+				clock = m.Starts[l]
+			}
+			clock += lap.Time
+			ok = lap.Time > 0
+		}
+		laps = append(laps, lap)
+	}
+	if filled == 0 {
+		return 0
+	}
+
+	for i := range laps {
+		laps[i].Number = uint(i) + 1
+	}
+	driver.replaceRun(m.Run, laps)
+
+	return filled
+}
+
+// lapAt returns the data logger lap that started at the time of day and the time of day it finished, or false if there isn't a valid lap.
+func (m *ManualRun) lapAt(clock time.Duration) (lap Lap, finish time.Duration, ok bool) {
+	l := m.lapStarting(clock)
+	if l < 0 || m.Times[l] <= 0 {
+		return lap, 0, false
+	}
+
+	lap = Lap{Run: m.Run, Time: m.Times[l], Status: lapCounted, Source: m.Source}
+	if m.Run == 0 {
+		lap.Status = lapQualifying
+	}
+
+	return lap, m.Starts[l] + m.Times[l], true
+}
+
+// lapStarting returns the index of the data logger lap that started at the time of day, or -1 if none did.
+func (m *ManualRun) lapStarting(clock time.Duration) int {
+	for l := range m.Starts {
+		if absDuration(m.Starts[l]-clock) < loggerTolerance {
+			return l
+		}
+	}
+
+	return -1
+}
+
+// absDuration returns the absolute value of d.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
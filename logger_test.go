@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLogger(t *testing.T) {
+	const clock = 10 * time.Hour
+
+	tests := []struct {
+		src     string
+		want    []loggedLap
+		isError bool
+	}{
+		{
+			src: "Lap,Lap Time,Start Time\n1,1:12.500,10:00:00.000\n2,1:05.432,10:01:12.500\n",
+			want: []loggedLap{
+				{Start: clock, Time: 72500 * time.Millisecond},
+				{Start: clock + 72500*time.Millisecond, Time: 65432 * time.Millisecond},
+			},
+		},
+		{
+			// AiM lists the session start above the column headings, and an incomplete in lap.
+			src: "\"Session\",\"Track Day\"\n\"Time\",\"10:00:00\"\n\n\"Lap #\",\"Lap Time\"\n\"1\",\"72.5\"\n\"2\",\"65.432\"\n\"3\",\"\"\n",
+			want: []loggedLap{
+				{Start: clock, Time: 72500 * time.Millisecond},
+				{Start: clock + 72500*time.Millisecond, Time: 65432 * time.Millisecond},
+				{Start: clock + 137932*time.Millisecond},
+			},
+		},
+		{src: "Lap;Laptime;Timestamp\n1;1:05,432;2023-03-12 10:00:00\n", want: []loggedLap{{Start: clock, Time: 65432 * time.Millisecond}}},
+		{src: "Lap,Lap Time\n1,1:05.432\n", isError: true},                 // Without any start time.
+		{src: "Lap,Lap Time,Start Time\n1,1:05.432,soon\n", isError: true}, // Invalid start time.
+		{src: "Name,Best\nJack Black,1:05.432\n", isError: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseLogger([]byte(test.src))
+		if (err != nil) != test.isError {
+			t.Errorf("parseLogger(%q) error = %v, want error %v", test.src, err, test.isError)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseLogger(%q) =\n%+v\nwant\n%+v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestFillGaps(t *testing.T) {
+	// Run 1 is missing the third lap without a time, and the fifth lap skipped in the Natsoft lap numbers, followed by the lap Natsoft lists between runs.
+	laps := timing(1, 72, 65, 0, 66, 67, 0)
+	laps[2].Status, laps[5].Status = lapExcluded, lapExcluded
+	laps[4].Natsoft, laps[5].Natsoft = 6, 7
+	driver := Driver{RaceNumber: "42", Timing: append(timing(0, 66), laps...)}
+
+	// The data logger starts with an out lap, and its clock is slightly different to the timing system's.
+	start := 10*time.Hour + 30*time.Minute
+	m := ManualRun{RaceNumber: "42", Run: 1, Source: sourceLogger}
+	for _, s := range []float64{40, 72.1, 65, 64.9, 66, 65.5, 67, 90} {
+		m.Times = append(m.Times, secondsToDuration(s))
+		m.Starts = append(m.Starts, start)
+		start += secondsToDuration(s)
+	}
+
+	if filled := driver.fillGaps(&m); filled != 2 {
+		t.Errorf("fillGaps() = %d, want 2", filled)
+	}
+
+	type lap struct {
+		Number, Natsoft uint
+		Time            time.Duration
+		Status, Source  string
+	}
+	want := []lap{
+		{1, 1, 72 * time.Second, lapCounted, ""},
+		{2, 2, 65 * time.Second, lapCounted, ""},
+		{3, 3, 64900 * time.Millisecond, lapCounted, sourceLogger},
+		{4, 4, 66 * time.Second, lapCounted, ""},
+		{5, 5, 65500 * time.Millisecond, lapCounted, sourceLogger},
+		{6, 6, 67 * time.Second, lapCounted, ""},
+		{7, 7, 0, lapExcluded, ""},
+	}
+	var got []lap
+	for _, l := range driver.Timing[1:] {
+		got = append(got, lap{l.Number, l.Natsoft, l.Time, l.Status, l.Source})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got laps\n%v\nwant\n%v", got, want)
+	}
+
+	// Data logger laps that don't line up with the run aren't used.
+	m.Starts = m.Starts[:0]
+	m.Times = m.Times[:0]
+	for i, s := range []float64{80, 81, 82} {
+		m.Times = append(m.Times, secondsToDuration(s))
+		m.Starts = append(m.Starts, start+time.Duration(i)*time.Minute)
+	}
+	if filled := driver.fillGaps(&m); filled != 0 {
+		t.Errorf("fillGaps() with unrelated laps = %d, want 0", filled)
+	}
+}
@@ -16,33 +16,86 @@ import (
 	"github.com/speedyhoon/utl/brwsr"
 )
 
-const (
-	competitorsFile = "competitors.txt"
-	filePermission  = 0600
-)
+const filePermission = 0600
+
+// stdin is shared between prompts so input buffered by one prompt isn't lost by the next.
+var stdin = bufio.NewReader(os.Stdin)
 
 func main() {
 	flag.Usage = func() {
 		fmt.Println(help)
 		flag.PrintDefaults()
 	}
+	advice := flag.String("advise", "", "Racing number to report the lap times that would change the driver's Percentage during the next run.")
+	entriesCSV := flag.String("entries", "", "CSV file exported from the online entry system to create the event's competitor list from.")
+	timingFiles := flag.String("timing", "", "Comma separated list of timing results files to use instead of the Natsoft results, one per run starting with Qualifying.")
+	format := flag.String("format", "", fmt.Sprintf("Format of the timing results files: %s, %s, %s, %s or %s. Detected from each file when empty.", formatNatsoft, formatOrbits, formatSpeedhive, formatPassings, formatColumns))
+	var loggers []loggerFile
+	flag.Func("logger", "Comma separated list of racing numbers and data logger lap CSV files to fill runs missed by the timing system, like 42=racechrono.csv.", func(s string) error {
+		return parseLoggerFiles(s, &loggers)
+	})
+	remaps := map[string]string{}
+	flag.Func("remap", "Comma separated list of entered racing numbers to score using a different racing number in the Natsoft results, like 47=74.", func(s string) error {
+		return parseRemaps(s, remaps)
+	})
+	loadConfig()
 	flag.Parse()
 
 	fmt.Println(championship)
 
-	src := getEventResults()
+	var timing []Driver
+	var meta Meta
+	if *timingFiles != "" {
+		timing, meta = importTiming(strings.Split(*timingFiles, ","), *format)
+	} else {
+		src := getEventResults()
+		meta = eventMeta(src)
+		timing = parseNatsoft(src)
+	}
 
-	comps := getCompetitorsFile()
+	var comps []Entry
+	switch {
+	case *advice != "":
+		// Advice is read-only, so it uses the event's saved list of competitors without prompting.
+		if comps = loadCompetitors(&meta); len(comps) == 0 {
+			return
+		}
+	case *entriesCSV != "":
+		comps = importEntries(*entriesCSV, &meta)
+	default:
+		comps = getCompetitors(&meta)
+	}
 	if len(comps) == 0 {
 		// Keep checking standard input for a list of competitors numbers to be entered.
 		for len(comps) == 0 {
-			comps = prepareComps(input())
+			var errs []string
+			comps, errs = parseCompetitors(input())
+			if len(errs) >= 1 {
+				fmt.Println(strings.Join(errs, newLine))
+				fmt.Println(competitorsSyntax)
+				comps = nil
+			}
 		}
 
-		checkErr(ioutil.WriteFile(competitorsFile, bytes.Join(comps, []byte(" ")), filePermission))
+		saveCompetitors(&meta, comps)
 	}
 
-	render(sortResults(src, comps))
+	remap(comps, remaps)
+	adjustments := loadAdjustments(&meta)
+	manual := append(loadManualRuns(&meta), loadLoggers(loggers, &meta)...)
+	event := sortResults(timing, meta, comps, &adjustments, manual)
+	if *advice != "" {
+		advise(&event, *advice)
+		return
+	}
+
+	if accepted := confirmRemaps(event.Suggestions); len(accepted) >= 1 {
+		remap(comps, accepted)
+		event = sortResults(timing, meta, comps, &adjustments, manual)
+	}
+	saveAdjustments(&meta, adjustments)
+
+	render(event)
 }
 
 func getEventResults() (src []byte) {
@@ -113,7 +166,7 @@ func getEventResults() (src []byte) {
 
 func input() []byte {
 	// ReadString will block until the delimiter is entered.
-	src, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	src, err := stdin.ReadBytes('\n')
 	checkErr(err)
 
 	// Remove the '\n' delimiter from the string.
@@ -124,39 +177,6 @@ func input() []byte {
 	return src
 }
 
-func getCompetitorsFile() [][]byte {
-	src, err := ioutil.ReadFile(competitorsFile)
-	if err != nil || len(src) == 0 {
-		fmt.Println("Please enter racing numbers separated by a space.")
-		return nil
-	}
-
-	fmt.Println("Using the list of competitors in", competitorsFile)
-
-	return prepareComps(src)
-}
-
-func prepareComps(src []byte) (competitors [][]byte) {
-	src = bytes.TrimSpace(src)
-	lines := bytes.Split(src, lineDelimiter)
-	for i := range lines {
-		lines[i] = bytes.TrimSpace(lines[i])
-		// Ignore any commented out lines prefixed with #.
-		if !bytes.HasPrefix(lines[i], []byte("#")) {
-			words := bytes.Split(lines[i], []byte(" "))
-			for j := range words {
-				words[j] = bytes.TrimSpace(words[j])
-				// Check if the competitor is already in the list.
-				if len(words[j]) >= 1 && !has(competitors, words[j]) {
-					competitors = append(competitors, words[j])
-				}
-			}
-		}
-	}
-
-	return
-}
-
 func exit(src []byte) {
 	switch strings.ToLower(string(src)) {
 	case "x", "exit", "q", "quit", "s", "stop", "h", "halt", "bye", "goodbye":
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// manualPrefix is the start of each event's file listing lap times hand timed with a stopwatch when a car's transponder failed, see Meta.eventFileName.
+const manualPrefix = "manual"
+
+// ManualRun contains the lap times hand timed for a car during a run.
+type ManualRun struct {
+	RaceNumber string
+	Run        uint // Zero based index, where zero is the Qualifying session.
+	Times      []time.Duration
+	Starts     []time.Duration // Time of day each data logger lap started.
+	Source     string          // sourceManual or sourceLogger.
+}
+
+// loadManualRuns returns the hand timed runs listed in the event's manual file.
+// Each line contains the racing number, the run (Q for Qualifying) and the lap times timed, starting with the formation lap after Qualifying, like:
+//
+//	42 2 1:12.5 1:05.4321 1:06.0100 65.98
+func loadManualRuns(meta *Meta) (runs []ManualRun) {
+	fileName := meta.eventFileName(manualPrefix)
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+
+	timed := map[string]int{} // Line number of each car's run already hand timed.
+	lines := bytes.Split(src, lineDelimiter)
+	for i := range lines {
+		line := string(bytes.TrimSpace(lines[i]))
+		if c := strings.Index(line, "#"); c >= 0 {
+			line = line[:c]
+		}
+		fields := strings.FieldsFunc(line, isEntrySeparator)
+		if len(fields) == 0 {
+			continue
+		}
+
+		run, err := parseManualRun(fields)
+		if err != nil {
+			fmt.Printf("%s line %d: %s\n", fileName, i+1, err)
+			continue
+		}
+
+		key := strings.ToLower(run.RaceNumber) + " " + runName(int(run.Run))
+		if line, ok := timed[key]; ok {
+			fmt.Printf("%s line %d: run %s of car %s is already hand timed on line %d\n", fileName, i+1, runName(int(run.Run)), run.RaceNumber, line)
+			continue
+		}
+		timed[key] = i + 1
+		runs = append(runs, run)
+	}
+
+	if len(runs) >= 1 {
+		fmt.Println("Using the hand timed laps in", fileName)
+	}
+
+	return runs
+}
+
+// parseManualRun returns a hand timed run given the fields: racing number, run, lap times.
+func parseManualRun(fields []string) (m ManualRun, err error) {
+	//nolint:gomnd // Ignore hardcoded numbers
+	if len(fields) < 3 {
+		return m, fmt.Errorf("expected racing number, run and lap times, got %q", strings.Join(fields, " "))
+	}
+
+	m.RaceNumber = fields[0]
+	m.Source = sourceManual
+	if !strings.EqualFold(fields[1], runQualify) {
+		run, err := strconv.ParseUint(fields[1], 10, 0)
+		if err != nil {
+			return m, fmt.Errorf("invalid run %q, expected %s or a run number like 1", fields[1], runQualify)
+		}
+		m.Run = uint(run)
+	}
+
+	for _, field := range fields[2:] {
+		lapTime, err := parseLapTime(field)
+		if err != nil || lapTime <= 0 {
+			return m, fmt.Errorf("invalid lap time %q, expected minutes and seconds like 1:05.4321 or seconds like 65.4321", field)
+		}
+		m.Times = append(m.Times, lapTime)
+	}
+
+	return m, nil
+}
+
+// description returns where the laps came from, like "hand timed laps".
+func (m *ManualRun) description() string {
+	if m.Source == sourceLogger {
+		return "data logger laps"
+	}
+	return "hand timed laps"
+}
+
+// manualCars returns a driver for each entered competitor with hand timed laps who isn't listed in the timing results.
+func manualCars(cars []Driver, entries []Entry, manual []ManualRun) []Driver {
+	for i := range manual {
+		if indexOfRacingNum(cars, manual[i].RaceNumber) >= 0 {
+			continue
+		}
+
+		e := indexOfEntry(entries, manual[i].RaceNumber)
+		if e < 0 {
+			fmt.Println("Ignoring the", manual[i].description(), "of racing number", manual[i].RaceNumber, "because it isn't entered in the event")
+			continue
+		}
+
+		cars = append(cars, Driver{
+			RaceNumber: entries[e].RaceNumber,
+			Name:       entries[e].Name,
+			Car:        entries[e].car(),
+		})
+	}
+
+	return cars
+}
+
+// mergeManual replaces the laps of each run hand timed for the driver with the manual lap times, then recalculates the driver's results.
+func (driver *Driver) mergeManual(manual []ManualRun) (diagnostics []Diagnostic) {
+	var changed bool
+	for i := range manual {
+		m := &manual[i]
+		if !strings.EqualFold(m.RaceNumber, driver.RaceNumber) {
+			continue
+		}
+
+		// Data logger laps only fill the laps missing from runs already timed.
+		if m.Source == sourceLogger && driver.timed(m.Run) {
+			message := fmt.Sprintf("ignoring %d %s during %s, no missing laps found", len(m.Times), m.description(), sessionName(m.Run))
+			if filled := driver.fillGaps(m); filled >= 1 {
+				message = fmt.Sprintf("%d missing laps during %s filled with %s", filled, sessionName(m.Run), m.description())
+				changed = true
+			}
+			diagnostics = append(diagnostics, Diagnostic{RaceNumber: driver.RaceNumber, Message: message})
+			continue
+		}
+
+		laps := make([]Lap, len(m.Times))
+		for l := range m.Times {
+			laps[l] = Lap{Run: m.Run, Number: uint(l) + 1, Time: m.Times[l], Status: lapCounted, Source: m.Source}
+			switch {
+			case m.Run >= 1 && l == 0:
+				// Like the timing system, the first lap of each run after Qualifying is the formation lap.
+				laps[l].Status = lapFormation
+			case m.Times[l] <= 0:
+				laps[l].Status = lapExcluded
+			case m.Run == 0:
+				laps[l].Status = lapQualifying
+			}
+		}
+
+		replaced := driver.replaceRun(m.Run, laps)
+		message := fmt.Sprintf("%d %s during %s", len(laps), m.description(), sessionName(m.Run))
+		if replaced >= 1 {
+			message += fmt.Sprintf(", replacing %d laps from the timing system", replaced)
+		}
+		diagnostics = append(diagnostics, Diagnostic{RaceNumber: driver.RaceNumber, Message: message})
+		changed = true
+	}
+
+	if changed {
+		driver.score()
+	}
+
+	return diagnostics
+}
+
+// replaceRun replaces the driver's laps during run with laps, keeping Timing in run order, and returns the quantity of laps replaced.
+func (driver *Driver) replaceRun(run uint, laps []Lap) (replaced int) {
+	timing := make([]Lap, 0, len(driver.Timing)+len(laps))
+	inserted := false
+	for _, lap := range driver.Timing {
+		if lap.Run == run {
+			replaced++
+			continue
+		}
+		if !inserted && lap.Run > run {
+			timing = append(timing, laps...)
+			inserted = true
+		}
+		timing = append(timing, lap)
+	}
+	if !inserted {
+		timing = append(timing, laps...)
+	}
+
+	driver.Timing = timing
+	return replaced
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseManualRun(t *testing.T) {
+	tests := []struct {
+		fields  []string
+		want    ManualRun
+		isError bool
+	}{
+		{fields: []string{"42", "2", "1:12.5", "1:05.4321", "65.98"}, want: ManualRun{RaceNumber: "42", Run: 2, Times: []time.Duration{72500 * time.Millisecond, 65432100 * time.Microsecond, 65980 * time.Millisecond}, Source: sourceManual}},
+		{fields: []string{"7", "q", "1:10.5"}, want: ManualRun{RaceNumber: "7", Times: []time.Duration{70500 * time.Millisecond}, Source: sourceManual}},
+		{fields: []string{"42", "2"}, isError: true},
+		{fields: []string{"42", "two", "1:05"}, isError: true},
+		{fields: []string{"42", "2", "1:05.4321", "fast"}, isError: true},
+		{fields: []string{"42", "2", "0"}, isError: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseManualRun(test.fields)
+		if (err != nil) != test.isError {
+			t.Errorf("parseManualRun(%q) error = %v, want error %v", test.fields, err, test.isError)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseManualRun(%q) = %+v, want %+v", test.fields, got, test.want)
+		}
+	}
+}
+
+func TestMergeManual(t *testing.T) {
+	driver := Driver{RaceNumber: "42", Timing: append(timing(0, 66), timing(1, 65, 67)...)}
+	driver.score()
+
+	manual := []ManualRun{
+		{RaceNumber: "42", Run: 2, Times: []time.Duration{80 * time.Second, 64 * time.Second, 68 * time.Second}, Source: sourceManual},
+		{RaceNumber: "42", Run: 1, Times: []time.Duration{70 * time.Second}, Source: sourceLogger}, // Already timed.
+		{RaceNumber: "7", Run: 1, Times: []time.Duration{70 * time.Second}, Source: sourceManual},
+	}
+	diagnostics := driver.mergeManual(manual)
+	if len(diagnostics) != 2 {
+		t.Errorf("got diagnostics %v, want 2", diagnostics)
+	}
+
+	var statuses []string
+	for _, lap := range driver.Timing {
+		statuses = append(statuses, lap.Status)
+	}
+	if want := []string{lapQualifying, lapCounted, lapCounted, lapFormation, lapCounted, lapCounted}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("got statuses %v, want %v", statuses, want)
+	}
+
+	// The hand timed formation lap isn't the slowest lap.
+	if driver.Laps != 4 || driver.Slowest != 68*time.Second || driver.Fastest != 64*time.Second || driver.Runs != 2 {
+		t.Errorf("got %d laps, %d runs, slowest %s and fastest %s, want 4 laps, 2 runs, slowest 1m8s and fastest 1m4s", driver.Laps, driver.Runs, driver.Slowest, driver.Fastest)
+	}
+	if driver.Sources != sourceManual || driver.RunStats[2].Source != sourceManual || driver.RunStats[1].Source != "" {
+		t.Errorf("got sources %q, run 1 %q and run 2 %q, want only run 2 hand timed", driver.Sources, driver.RunStats[1].Source, driver.RunStats[2].Source)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2006-01-02" // Date format used in file names and the `date` setting.
+
+var (
+	// Event dates like `12/03/2023`, `2023-03-12`, `12 March 2023`, `Sunday 12th Mar 2023` or `March 12, 2023`.
+	reDate       = regexp.MustCompile(`(?i)\b(\d{1,2}/\d{1,2}/\d{4}|\d{4}-\d{2}-\d{2}|\d{1,2}(?:st|nd|rd|th)? (?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]* \d{4}|(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]* \d{1,2}(?:st|nd|rd|th)?,? \d{4})\b`)
+	reOrdinalDay = regexp.MustCompile(`(?i)(\d)(st|nd|rd|th)\b`)
+	reCircuit    = regexp.MustCompile(`(?i)\b(circuit|raceway|motorsport park|speedway|park|island)\b`)
+	reClub       = regexp.MustCompile(`(?i)\b(club|association|society|inc|incorporated|register|tsoa)\b`)
+	reSession    = regexp.MustCompile(`(?i)^(session|practice|qualifying|race|sprint|group|run|heat|regularity|test)\b`)
+
+	dateLayouts = []string{"2/1/2006", dateFormat, "2 January 2006", "2 Jan 2006", "January 2, 2006", "January 2 2006", "Jan 2, 2006", "Jan 2 2006"}
+
+	reFileName = regexp.MustCompile(`[<>:"/\\|?*]+`) // Characters not allowed in Windows file names.
+)
+
+// Meta contains details of the event found in the Natsoft results header, or from the settings.
+type Meta struct {
+	Title    string // The event name, defaults to the first line of the Natsoft results.
+	Circuit  string
+	Club     string // Organising club.
+	Date     time.Time
+	Sessions []string        // Session headings listed in the Natsoft results.
+	Starts   []time.Duration // Time of day each run started, from the schedule setting or the passings.
+}
+
+// eventMeta returns the event details found in the Natsoft results, overridden by any settings.
+func eventMeta(results []byte) (meta Meta) {
+	header := results
+	if i := reHasDrivers.FindIndex(results); i != nil {
+		header = results[:i[0]]
+	}
+
+	var titleCircuit string // Circuit named on the title line, used when the circuit isn't listed on its own line.
+	lines := bytes.Split(header, lineDelimiter)
+	for i := range lines {
+		line := string(bytes.TrimSpace(lines[i]))
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case meta.Title == "":
+			// The date is often printed on the title line, like `Sports Car Track Day - 12/03/2023`.
+			if date := reDate.FindString(line); date != "" && meta.Date.IsZero() {
+				meta.Date = parseDate(date)
+				line = strings.Trim(reDate.ReplaceAllString(line, ""), " -,|")
+			}
+			meta.Title = line
+			if reCircuit.MatchString(line) {
+				titleCircuit = line
+			}
+		case meta.Date.IsZero() && reDate.MatchString(line):
+			meta.Date = parseDate(reDate.FindString(line))
+		case meta.Circuit == "" && reCircuit.MatchString(line):
+			meta.Circuit = line
+		case meta.Club == "" && reClub.MatchString(line):
+			meta.Club = line
+		}
+	}
+	if meta.Circuit == "" {
+		meta.Circuit = titleCircuit
+	}
+
+	// Session headings can be listed anywhere between the drivers lap times.
+	for _, line := range bytes.Split(reHasDrivers.ReplaceAll(results, nil), lineDelimiter) {
+		line = bytes.TrimSpace(line)
+		if reSession.Match(line) && !reLapTime.Match(line) {
+			meta.Sessions = append(meta.Sessions, string(line))
+		}
+	}
+
+	// Settings override any event details found.
+	meta.override()
+
+	return meta
+}
+
+// override replaces the event details with any set in the settings.
+func (m *Meta) override() {
+	if cfg.Event != "" {
+		m.Title = cfg.Event
+	}
+	if cfg.Circuit != "" {
+		m.Circuit = cfg.Circuit
+	}
+	if cfg.Club != "" {
+		m.Club = cfg.Club
+	}
+	if cfg.Date != "" {
+		date, err := time.Parse(dateFormat, cfg.Date)
+		if err != nil {
+			fmt.Println("Invalid date", cfg.Date, "expected format", dateFormat)
+		} else {
+			m.Date = date
+		}
+	}
+	if len(cfg.Schedule) >= 1 {
+		m.Starts = nil
+		for _, s := range cfg.Schedule {
+			start, err := parseClock(s)
+			if err != nil {
+				fmt.Println("Invalid schedule", err)
+				m.Starts = nil
+				return
+			}
+			m.Starts = append(m.Starts, start)
+		}
+	}
+}
+
+// runAt returns the run in progress at the time of day, or false if it's before the first run started.
+func (m *Meta) runAt(clock time.Duration) (run uint, ok bool) {
+	for i := range m.Starts {
+		if clock < m.Starts[i] {
+			break
+		}
+		run, ok = uint(i), true
+	}
+
+	return run, ok
+}
+
+// parseDate returns the date, or a zero time when the format isn't recognised.
+func parseDate(s string) time.Time {
+	s = reOrdinalDay.ReplaceAllString(s, "$1")
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, s); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
+
+// Subtitle returns the circuit, date and organising club, like: `Phillip Island Grand Prix Circuit - 12 March 2023 - TSOA`.
+func (m *Meta) Subtitle() string {
+	var parts []string
+	if m.Circuit != "" {
+		parts = append(parts, m.Circuit)
+	}
+	if !m.Date.IsZero() {
+		parts = append(parts, m.Date.Format("2 January 2006"))
+	}
+	if m.Club != "" {
+		parts = append(parts, m.Club)
+	}
+
+	return strings.Join(parts, " - ")
+}
+
+// day returns the event date, or today's date when the event date is unknown.
+func (m *Meta) day() time.Time {
+	if m.Date.IsZero() {
+		return time.Now()
+	}
+
+	return m.Date
+}
+
+// eventFileName returns the name of a text file kept for each event, starting with prefix followed by the event date and name,
+// like `adjustments-2023-03-12 Sports Car Track Day.txt`.
+func (m *Meta) eventFileName(prefix string) string {
+	name := prefix + "-" + m.day().Format(dateFormat)
+	if title := strings.TrimSpace(reFileName.ReplaceAllString(m.Title, " ")); title != "" {
+		name += " " + title
+	}
+
+	return name + ".txt"
+}
+
+// fileName returns the results file name without an extension, using the event date (or today's date) and the circuit.
+func (m *Meta) fileName() string {
+	name := "results-" + m.day().Format(dateFormat)
+	if m.Circuit != "" {
+		name += " " + strings.TrimSpace(reFileName.ReplaceAllString(m.Circuit, " "))
+	}
+
+	return name + time.Now().Format(" 3;4;05")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEventMeta(t *testing.T) {
+	const driverLine = "\n 42 Joe Bloggs  1:10.1234 1:05.4321 \n"
+	date := time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header               string
+		title, circuit, club string
+		date                 time.Time
+	}{
+		{
+			header:  "Sports Car Track Day\nSunday 12th March 2023\nPhillip Island Grand Prix Circuit\nTriumph Sports Owners Association",
+			title:   "Sports Car Track Day",
+			circuit: "Phillip Island Grand Prix Circuit",
+			club:    "Triumph Sports Owners Association",
+			date:    date,
+		},
+		{
+			header:  "Sports Car Track Day\nWinton Motor Raceway\n12/03/2023",
+			title:   "Sports Car Track Day",
+			circuit: "Winton Motor Raceway",
+			date:    date,
+		},
+		{
+			header:  "Sports Car Track Day - 12/03/2023\nWinton Motor Raceway",
+			title:   "Sports Car Track Day",
+			circuit: "Winton Motor Raceway",
+			date:    date,
+		},
+		{
+			header:  "Winton Raceway Track Day 2023-03-12",
+			title:   "Winton Raceway Track Day",
+			circuit: "Winton Raceway Track Day",
+			date:    date,
+		},
+		{
+			header: "12 Mar 2023\nSports Car Track Day",
+			title:  "Sports Car Track Day",
+			date:   date,
+		},
+		{
+			header: "Sports Car Track Day",
+			title:  "Sports Car Track Day",
+		},
+	}
+
+	for _, test := range tests {
+		meta := eventMeta([]byte(test.header + driverLine))
+		if meta.Title != test.title || meta.Circuit != test.circuit || meta.Club != test.club || !meta.Date.Equal(test.date) {
+			t.Errorf("eventMeta(%q) = %q, %q, %q, %s, want %q, %q, %q, %s", test.header, meta.Title, meta.Circuit, meta.Club, meta.Date, test.title, test.circuit, test.club, test.date)
+		}
+	}
+
+	// Session headings listed between the drivers lap times.
+	meta := eventMeta([]byte("Sports Car Track Day\nPractice 1" + driverLine + "Race 2 - Regularity" + driverLine))
+	if want := []string{"Practice 1", "Race 2 - Regularity"}; !reflect.DeepEqual(meta.Sessions, want) {
+		t.Errorf("got sessions %q, want %q", meta.Sessions, want)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	date := time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC)
+	for _, s := range []string{"12/03/2023", "2023-03-12", "12 March 2023", "12th Mar 2023", "March 12, 2023", "Mar 12th 2023"} {
+		if got := parseDate(s); !got.Equal(date) {
+			t.Errorf("parseDate(%q) = %s, want %s", s, got, date)
+		}
+	}
+	if got := parseDate("Sunday"); !got.IsZero() {
+		t.Errorf("parseDate(%q) = %s, want a zero time", "Sunday", got)
+	}
+}
+
+func TestSubtitle(t *testing.T) {
+	tests := []struct {
+		meta Meta
+		want string
+	}{
+		{Meta{Circuit: "Winton Motor Raceway", Date: time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC), Club: "TSOA"}, "Winton Motor Raceway - 12 March 2023 - TSOA"},
+		{Meta{Club: "TSOA"}, "TSOA"},
+		{Meta{}, ""},
+	}
+
+	for _, test := range tests {
+		if got := test.meta.Subtitle(); got != test.want {
+			t.Errorf("Subtitle() = %q, want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Column headings used by MyLaps Orbits lap time exports.
+var (
+	orbitsNumber    = []string{"No.", "No", "Number", "Nr", "Start Number", "Competitor Number"}
+	orbitsName      = []string{"Name", "Driver", "Competitor"}
+	orbitsFirstName = []string{"First Name", "Firstname"}
+	orbitsLastName  = []string{"Last Name", "Lastname", "Surname"}
+	orbitsLap       = []string{"Lap", "Laps", "Lap No.", "Lap Number"}
+	orbitsLapTime   = []string{"Lap Time", "LapTime", "Laptime", "Time"}
+)
+
+// parseOrbits returns the laps in a MyLaps Orbits lap time CSV export of a single run.
+func parseOrbits(src []byte, run uint) (laps []timedLap, err error) {
+	r := newCSVReader(src)
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	number := columnIndex(header, orbitsNumber...)
+	name := columnIndex(header, orbitsName...)
+	firstName := columnIndex(header, orbitsFirstName...)
+	lastName := columnIndex(header, orbitsLastName...)
+	lap := columnIndex(header, orbitsLap...)
+	lapTime := columnIndex(header, orbitsLapTime...)
+	if number < 0 || lapTime < 0 {
+		return nil, fmt.Errorf("expected columns %q and %q in the MyLaps Orbits export", orbitsNumber[0], orbitsLapTime[0])
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return laps, err
+		}
+
+		t := timedLap{
+			RaceNumber: column(record, number),
+			Name:       column(record, name),
+			Run:        run,
+		}
+		if t.RaceNumber == "" {
+			continue
+		}
+		if t.Name == "" {
+			t.Name = strings.TrimSpace(column(record, firstName) + " " + column(record, lastName))
+		}
+		if n, err := strconv.ParseUint(column(record, lap), 10, 0); err == nil {
+			t.Number = uint(n)
+		}
+		// Laps without a valid time, like "In Pit", are excluded.
+		t.Time, _ = parseLapTime(column(record, lapTime)) //nolint:errcheck // Excluded when invalid.
+
+		laps = append(laps, t)
+	}
+
+	return laps, nil
+}
+
+// isOrbits returns true if the first line of the CSV export has the racing number and lap time columns of a MyLaps Orbits export.
+func isOrbits(src []byte) bool {
+	header, err := newCSVReader(src).Read()
+	return err == nil && columnIndex(header, orbitsNumber...) >= 0 && columnIndex(header, orbitsLapTime...) >= 0
+}
+
+// newCSVReader returns a reader for comma, semicolon or tab separated values, detected from the first line.
+func newCSVReader(src []byte) *csv.Reader {
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf")) // Ignore any UTF-8 byte order mark.
+	r := csv.NewReader(bytes.NewReader(src))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	firstLine := src
+	if i := bytes.IndexByte(src, '\n'); i >= 0 {
+		firstLine = src[:i]
+	}
+	switch {
+	case bytes.Count(firstLine, []byte("\t")) > bytes.Count(firstLine, []byte(",")):
+		r.Comma = '\t'
+	case bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")):
+		r.Comma = ';'
+	}
+
+	return r
+}
+
+// columnIndex returns the index of the first column in header matching any of the headings case-insensitively, or -1 when not found.
+func columnIndex(header []string, headings ...string) int {
+	for _, heading := range headings {
+		for i := range header {
+			if strings.EqualFold(strings.TrimSpace(header[i]), strings.TrimSpace(heading)) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
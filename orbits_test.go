@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseOrbits(t *testing.T) {
+	src, err := ioutil.ReadFile("testdata/orbits.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	laps, err := parseOrbits(src, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []timedLap{
+		{RaceNumber: "42", Name: "Joe Bloggs", Run: 2, Number: 1, Time: 72500 * time.Millisecond},
+		{RaceNumber: "42", Name: "Joe Bloggs", Run: 2, Number: 2, Time: 65432 * time.Millisecond},
+		{RaceNumber: "512", Name: "Sam Smith", Run: 2, Number: 1, Time: 75 * time.Second},
+		{RaceNumber: "512", Name: "Sam Smith", Run: 2, Number: 2}, // In Pit is excluded.
+	}
+	if !reflect.DeepEqual(laps, want) {
+		t.Errorf("parseOrbits() = %+v, want %+v", laps, want)
+	}
+
+	tests := []struct {
+		src     string
+		laps    int
+		isError bool
+	}{
+		{src: "No.,Name,Lap,Lap Time\n7,Ann Lee,1,1:05.432\n", laps: 1},
+		{src: "\xef\xbb\xbfNo\tName\tLaptime\n7\tAnn Lee\t65.432\n8\tBob Day\t66.1\n", laps: 2},
+		{src: "No.,Name,Lap\n7,Ann Lee,1\n", isError: true},
+		{src: "", isError: true},
+	}
+	for _, test := range tests {
+		laps, err := parseOrbits([]byte(test.src), 0)
+		if (err != nil) != test.isError || len(laps) != test.laps {
+			t.Errorf("parseOrbits(%q) = %d laps, error %v, want %d laps and error %v", test.src, len(laps), err, test.laps, test.isError)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column headings used by decoder passings exports.
+var (
+	passingsTransponder = []string{"Transponder", "Transponder ID", "Transponder No.", "Transponder Number", "Chip", "Chip Code"}
+	passingsTime        = []string{"Time", "Timestamp", "Passing Time", "Time of Day", "RTC Time"}
+)
+
+const (
+	unixTime = 1e9 // The smallest timestamp read as Unix time in seconds, instead of seconds since midnight.
+
+	// pitStopRatio is how many times longer than the car's median lap a lap between passings must be to be excluded as a pit stop.
+	// Laps about double the median lap are left for the transponder glitch check.
+	pitStopRatio = 2.5
+	notePitStop  = "pit stop"
+)
+
+// Timestamp layouts accepted in passings exports and the schedule setting, besides seconds since midnight.
+var passingsLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"02/01/2006 15:04:05.999999999",
+	"15:04:05.999999999",
+	"15:04",
+}
+
+// passing is a single crossing of the timing line by a transponder.
+type passing struct {
+	Transponder string
+	Clock       time.Duration // Time of day.
+}
+
+// parsePassings returns the laps between each car's passings, and the time of day each run found started, numbering the runs from run.
+// A new run starts when no transponders cross the line for cfg.RunGap minutes.
+// Transponders are converted to racing numbers using cfg.Transponders.
+func parsePassings(src []byte, run uint) (laps []timedLap, starts []time.Duration, err error) {
+	if cfg.RunGap <= 0 {
+		return nil, nil, fmt.Errorf("invalid runGap setting %v, expected the minutes between runs, like 10", cfg.RunGap)
+	}
+
+	passings, err := readPassings(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(passings) == 0 {
+		return nil, nil, fmt.Errorf("no passings found")
+	}
+	sort.SliceStable(passings, func(i, j int) bool {
+		return passings[i].Clock < passings[j].Clock
+	})
+
+	gap := time.Duration(cfg.RunGap * float64(time.Minute))
+	starts = append(starts, passings[0].Clock)
+	last := make(map[string]time.Duration) // Time of each car's previous passing during the run.
+	number := make(map[string]uint)        // Quantity of laps completed by each car during the run.
+	unknown := make(map[string]bool)       // Transponders missing from cfg.Transponders.
+
+	for i, p := range passings {
+		if i >= 1 && p.Clock-passings[i-1].Clock >= gap {
+			run++
+			starts = append(starts, p.Clock)
+			last = make(map[string]time.Duration)
+			number = make(map[string]uint)
+		}
+
+		raceNumber, ok := cfg.Transponders[p.Transponder]
+		if !ok {
+			if !unknown[p.Transponder] {
+				fmt.Printf("Ignoring the passings of transponder %s because it isn't listed in the transponders setting%s", p.Transponder, newLine)
+				unknown[p.Transponder] = true
+			}
+			continue
+		}
+
+		// The first passing of each run starts the car's first lap.
+		if previous, ok := last[raceNumber]; ok {
+			number[raceNumber]++
+			laps = append(laps, timedLap{
+				RaceNumber: raceNumber,
+				Run:        run,
+				Number:     number[raceNumber],
+				Time:       p.Clock - previous,
+			})
+		}
+		last[raceNumber] = p.Clock
+	}
+
+	excludePitStops(laps)
+	return laps, starts, nil
+}
+
+// excludePitStops marks laps much longer than the car's median lap, like a pit stop during a run, to be excluded.
+func excludePitStops(laps []timedLap) {
+	seconds := make(map[string][]float64)
+	for i := range laps {
+		seconds[laps[i].RaceNumber] = append(seconds[laps[i].RaceNumber], laps[i].Time.Seconds())
+	}
+
+	medians := make(map[string]float64, len(seconds))
+	for raceNumber := range seconds {
+		medians[raceNumber] = median(seconds[raceNumber])
+	}
+
+	for i := range laps {
+		if laps[i].Time.Seconds() > pitStopRatio*medians[laps[i].RaceNumber] {
+			laps[i].Note = notePitStop
+		}
+	}
+}
+
+// readPassings returns the passings listed in a decoder's CSV export.
+// Exports without a header row are read as the transponder followed by the time of the passing.
+func readPassings(src []byte) (passings []passing, err error) {
+	r := newCSVReader(src)
+	transponder, clock := 0, 1
+
+	isHeader := true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return passings, err
+		}
+
+		if isHeader {
+			isHeader = false
+			if t, c := columnIndex(record, passingsTransponder...), columnIndex(record, passingsTime...); t >= 0 && c >= 0 {
+				transponder, clock = t, c
+				continue
+			}
+		}
+
+		p := passing{Transponder: column(record, transponder)}
+		if p.Transponder == "" {
+			continue
+		}
+		if p.Clock, err = parseClock(column(record, clock)); err != nil {
+			return passings, err
+		}
+		passings = append(passings, p)
+	}
+
+	return passings, nil
+}
+
+// isPassings returns true if the first line of the CSV export has a transponder column but no lap column,
+// or is a passing without a header row, like `1234567,10:01:02.345`.
+func isPassings(src []byte) bool {
+	header, err := newCSVReader(src).Read()
+	if err != nil {
+		return false
+	}
+	if columnIndex(header, passingsTransponder...) >= 0 {
+		return columnIndex(header, orbitsLap...) < 0
+	}
+
+	_, err = parseClock(column(header, 1))
+	return len(header) >= 2 && column(header, 0) != "" && err == nil
+}
+
+// parseClock returns the time of day of a timestamp, like 10:01:02.345, 2023-03-12 10:01:02.345 or Unix time in seconds.
+func parseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil && seconds >= unixTime {
+		t := time.Unix(0, int64(seconds*float64(time.Second))).Local()
+		return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())), nil
+	}
+	for _, layout := range passingsLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())), nil
+		}
+	}
+
+	d, err := parseLapTime(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid passing time %q", s)
+	}
+
+	return d, nil
+}
+
+// sessionHeading returns the heading of a run found in the passings, like "Run 1 from 10:42:05".
+func sessionHeading(run uint, start time.Duration) string {
+	return fmt.Sprintf("%s from %s", sessionTitle(run), formatClock(start))
+}
+
+// formatClock returns the time of day, like 10:42:05.
+func formatClock(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04:05")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParsePassings(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.RunGap = 10
+	cfg.Transponders = map[string]string{"1234567": "42", "7654321": "512"}
+
+	const src = `Transponder,Time
+1234567,09:00:00.000
+7654321,09:00:02.000
+1234567,09:01:05.000
+7654321,09:01:08.500
+1234567,09:02:10.500
+9999999,09:02:11.000
+7654321,09:02:14.000
+1234567,09:30:00.000
+1234567,09:31:06.000
+1234567,09:35:10.000
+1234567,09:36:15.000
+`
+	laps, starts, err := parsePassings([]byte(src), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []timedLap{
+		{RaceNumber: "42", Run: 0, Number: 1, Time: 65 * time.Second},
+		{RaceNumber: "512", Run: 0, Number: 1, Time: 66500 * time.Millisecond},
+		{RaceNumber: "42", Run: 0, Number: 2, Time: 65500 * time.Millisecond},
+		{RaceNumber: "512", Run: 0, Number: 2, Time: 65500 * time.Millisecond},
+		{RaceNumber: "42", Run: 1, Number: 1, Time: 66 * time.Second},
+		// A pit stop during the run.
+		{RaceNumber: "42", Run: 1, Number: 2, Time: 4*time.Minute + 4*time.Second, Note: notePitStop},
+		{RaceNumber: "42", Run: 1, Number: 3, Time: 65 * time.Second},
+	}
+	if !reflect.DeepEqual(laps, want) {
+		t.Errorf("parsePassings() laps =\n%+v\nwant\n%+v", laps, want)
+	}
+	if wantStarts := []time.Duration{9 * time.Hour, 9*time.Hour + 30*time.Minute}; !reflect.DeepEqual(starts, wantStarts) {
+		t.Errorf("parsePassings() starts = %v, want %v", starts, wantStarts)
+	}
+
+	// Passings without a header row.
+	laps, _, err = parsePassings([]byte("1234567,09:00:00\n1234567,09:01:05\n"), 2)
+	if err != nil || len(laps) != 1 || laps[0].Run != 2 || laps[0].Time != 65*time.Second {
+		t.Errorf("parsePassings() without a header = %+v, %v, want 1 lap of 1m5s during run 2", laps, err)
+	}
+
+	for _, gap := range []float64{0, -5} {
+		cfg.RunGap = gap
+		if _, _, err = parsePassings([]byte(src), 0); err == nil {
+			t.Errorf("parsePassings() with a run gap of %v minutes, want an error", gap)
+		}
+	}
+}
+
+func TestIsPassings(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"Transponder,Time\n1234567,10:01:02.345\n", true},
+		{"Chip Code;Passing Time\n", true},
+		{"1234567,10:01:02.345\n", true},
+		{"1234567,1678615262.345,-62\n", true},
+		{"Transponder,Lap,Lap Time\n", false},
+		{"No.,Name,Lap,Lap Time\n7,Ann Lee,1,1:05.432\n", false},
+		{"7\n", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := isPassings([]byte(test.src)); got != test.want {
+			t.Errorf("isPassings(%q) = %v, want %v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		isError bool
+	}{
+		{s: "10:01:02.345", want: 10*time.Hour + 62345*time.Millisecond},
+		{s: "2023-03-12 10:01:02.345", want: 10*time.Hour + 62345*time.Millisecond},
+		{s: "2023-03-12T10:01:02Z", want: 10*time.Hour + 62*time.Second},
+		{s: "12/03/2023 10:01:02", want: 10*time.Hour + 62*time.Second},
+		{s: " 09:00 ", want: 9 * time.Hour},
+		{s: "36062.345", want: 10*time.Hour + 62345*time.Millisecond},
+		{s: "Time", isError: true},
+		{s: "", isError: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseClock(test.s)
+		if (err != nil) != test.isError {
+			t.Errorf("parseClock(%q) error = %v, want error %v", test.s, err, test.isError)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("parseClock(%q) = %s, want %s", test.s, got, test.want)
+		}
+	}
+
+	// Unix time in seconds is converted to the local time of day.
+	unix := time.Date(2023, 3, 12, 10, 1, 2, 0, time.Local).Unix()
+	if got, err := parseClock(strconv.FormatInt(unix, 10)); err != nil || got != 10*time.Hour+62*time.Second {
+		t.Errorf("parseClock(%d) = %s, %v, want 10h1m2s", unix, got, err)
+	}
+}
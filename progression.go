@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/speedyhoon/utl"
+)
+
+// Standing is a driver's position at a point in time, where zero is not classified.
+type Standing struct {
+	Position uint
+	IsEqual  bool
+}
+
+// Ordinal returns the position, like: 1st, =2nd, or a hyphen when not classified.
+func (s Standing) Ordinal() string {
+	if s.Position == 0 {
+		return "-"
+	}
+	return utl.Ordinal(s.Position, s.IsEqual)
+}
+
+// progression assigns each driver's Progress, by calculating the standings as they were after each run.
+func progression(event *Event) {
+	var runs uint
+	for _, drivers := range [][]Driver{event.Drivers, event.NotClassified} {
+		for i := range drivers {
+			if r := drivers[i].lastRun(); r > runs {
+				runs = r
+			}
+		}
+	}
+
+	for run := uint(1); run <= runs; run++ {
+		standings := standingsAfter(event, run)
+		for _, drivers := range [][]Driver{event.Drivers, event.NotClassified} {
+			for i := range drivers {
+				var standing Standing
+				if j := indexOfDriver(standings, &drivers[i]); j >= 0 {
+					standing = Standing{Position: standings[j].Position, IsEqual: standings[j].IsEqual}
+				}
+				drivers[i].Progress = append(drivers[i].Progress, standing)
+			}
+		}
+	}
+}
+
+// lastRun returns the last run the driver completed a lap in.
+func (driver *Driver) lastRun() (run uint) {
+	if l := len(driver.Timing); l >= 1 {
+		return driver.Timing[l-1].Run
+	}
+
+	return 0
+}
+
+// standingsAfter returns the classified drivers ranked using only the laps completed up to and including run.
+func standingsAfter(event *Event, run uint) (standings []Driver) {
+	for _, drivers := range [][]Driver{event.Drivers, event.NotClassified} {
+		for i := range drivers {
+			d := Driver{
+				RaceNumber: drivers[i].RaceNumber,
+				Name:       drivers[i].Name,
+				Car:        drivers[i].Car,
+			}
+
+			for l := range drivers[i].Timing {
+				if drivers[i].Timing[l].Run <= run {
+					d.Timing = append(d.Timing, drivers[i].Timing[l])
+				}
+			}
+
+			d.score()
+			if d.classify() == "" {
+				standings = append(standings, d)
+			}
+		}
+	}
+
+	sortDrivers(standings)
+	rankDrivers(standings)
+	return standings
+}
+
+// positionChange returns how many positions the driver gained or lost during the last run, like: +2, -1 or =.
+func (driver *Driver) positionChange() string {
+	if len(driver.Progress) < 2 {
+		return ""
+	}
+
+	previous := driver.Progress[len(driver.Progress)-2].Position
+	switch {
+	case previous == 0:
+		return "New"
+	case previous > driver.Position:
+		return fmt.Sprintf("+%d", previous-driver.Position)
+	case previous < driver.Position:
+		return fmt.Sprintf("-%d", driver.Position-previous)
+	}
+
+	return "="
+}
+
+// progressName returns the heading for the standings after run.
+func progressName(run int) string {
+	return fmt.Sprintf("%s %d", hRun, run+1)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProgression(t *testing.T) {
+	defer func(c Config) { cfg = c }(cfg)
+	cfg.RequireQualify, cfg.MinRuns, cfg.MinLaps, cfg.Ranking = true, 1, 1, rankStandard
+
+	drivers := []Driver{
+		{RaceNumber: "1", Timing: append(append(timing(0, 66), timing(1, 64, 68)...), timing(2, 65)...)},
+		{RaceNumber: "2", Timing: append(append(timing(0, 66), timing(1, 65, 67)...), timing(2, 72)...)},
+		{RaceNumber: "3", Timing: append(timing(0, 66), timing(2, 64, 66)...)}, // Missed the first run.
+	}
+	for i := range drivers {
+		drivers[i].score()
+	}
+	sortDrivers(drivers)
+	rankDrivers(drivers)
+
+	event := Event{Drivers: drivers}
+	progression(&event)
+
+	want := map[string]struct {
+		ordinals []string
+		change   string
+	}{
+		"1": {[]string{"2nd", "1st"}, "+1"},
+		"2": {[]string{"1st", "2nd"}, "-1"},
+		"3": {[]string{"-", "3rd"}, "New"},
+	}
+	for i := range event.Drivers {
+		d := &event.Drivers[i]
+		var ordinals []string
+		for _, s := range d.Progress {
+			ordinals = append(ordinals, s.Ordinal())
+		}
+		w := want[d.RaceNumber]
+		if !reflect.DeepEqual(ordinals, w.ordinals) {
+			t.Errorf("car %s: got standings %v, want %v", d.RaceNumber, ordinals, w.ordinals)
+		}
+		if change := d.positionChange(); change != w.change {
+			t.Errorf("car %s: got change %q, want %q", d.RaceNumber, change, w.change)
+		}
+	}
+}
@@ -3,61 +3,123 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
-	"time"
-
-	"github.com/speedyhoon/utl"
 )
 
 const (
 	// Column headings.
-	hPosition     = "Pos"
-	hRacingNumber = "#"
-	hDriver       = "Driver"
-	hQualify      = "Qualify"
-	hFastest      = "Fastest"
-	hSlowest      = "Slowest"
-	hAverage      = "Slow Ave"
-	hPercentage   = "Percentage"
-	hRuns         = "Runs"
-	hLaps         = "Laps"
-	hSeconds      = "Secs"
-	hMissing      = "Missing:"
-	hCompetitors  = "Competitors:"
+	hPosition      = "Pos"
+	hRacingNumber  = "#"
+	hDriver        = "Driver"
+	hQualify       = "Qualify"
+	hFastest       = "Fastest"
+	hSlowest       = "Slowest"
+	hAverage       = "Slow Ave"
+	hPercentage    = "Percentage"
+	hRuns          = "Runs"
+	hLaps          = "Laps"
+	hSeconds       = "Secs"
+	hMissing       = "Missing:"
+	hCompetitors   = "Competitors:"
+	hNotClassified = "Not Classified:"
+	hIneligible    = "Ran but ineligible:"
+	hAbsent        = "Entered but ineligible, didn't run:"
+	hReason        = "Reason"
+	hCar           = "Car"
+	hHandicap      = "Handicap"
+	hScore         = "Handicapped"
+	hRun           = "Run"
+	hAverageLap    = "Average"
+	hRunStats      = "Runs:"
+	hLap           = "Lap"
+	hStatus        = "Status"
+	hMean          = "Mean"
+	hMedian        = "Median"
+	hStdDev        = "Std Dev"
+	hCV            = "CV %"
+	hAwards        = "Awards:"
+	hAnalysis      = "Analysis:"
+	hGapAhead      = "Gap Ahead"
+	hGapLeader     = "Gap Leader"
+	hFastestNeeded = "Fastest Needed"
+	hSlowestNeeded = "Slowest Needed"
+	hProgression   = "Progression:"
+	hChange        = "+/-"
+	hDiagnostics   = "Diagnostics:"
+	hNote          = "Note"
+	hSource        = "Source"
+	hRunLap        = "Run Lap"
+	hSessions      = "Sessions:"
 )
 
-func render(drivers []Driver, eventName string, missingCars []string, longestNameLen uint) {
-	l := uint(len(drivers))
-	excel, spreadsheetRow := excelHeading(eventName)
-	html := htmlHeading(eventName, l)
-	txt := txtHeading(eventName, l, longestNameLen)
+func render(event Event) {
+	drivers := event.Drivers
+	l := uint(len(drivers) + len(event.NotClassified))
+	sources := event.hasSources()
+	excel, spreadsheetRow := excelHeading(event.Name, &event.Meta, sources)
+	html := htmlHeading(event.Name, &event.Meta, l, sources)
+	txt := txtHeading(event.Name, &event.Meta, l, event.LongestNameLen, event.LongestCarLen, sources)
 
-	var isEqual bool
 	for i := range drivers {
-		// Calculate if the next or previous competitor had an identical score.
-		if i >= 1 && drivers[i].Percentage == drivers[i-1].Percentage && drivers[i].Runs == drivers[i-1].Runs && drivers[i].Laps == drivers[i-1].Laps {
-			isEqual = true
-		} else {
-			// Ignore positions occupied by drivers with equal results, like: =1st, =1st, =3rd, =3rd, =5th, =5th. Change to `position++` for the opposite effect like: =1st, =1st, =2nd, =2nd, =3rd, =3rd.
-			isEqual = i+1 < len(drivers) && drivers[i].Percentage == drivers[i+1].Percentage && drivers[i].Runs == drivers[i+1].Runs && drivers[i].Laps == drivers[i+1].Laps
-		}
-
-		ord := utl.Ordinal0(i, isEqual)
-
-		htmlRow(html, &drivers[i], ord)
-		textRow(txt, &drivers[i], ord, longestNameLen)
-		excelRow(excel, &drivers[i], ord, &spreadsheetRow)
+		ord := drivers[i].Ordinal()
+
+		htmlRow(html, &drivers[i], ord, sources)
+		textRow(txt, &drivers[i], ord, event.LongestNameLen, event.LongestCarLen, sources)
+		excelRow(excel, &drivers[i], ord, &spreadsheetRow, sources)
 	}
 
-	htmlFooter(html, missingCars)
-	textFooter(txt, missingCars)
-	excelFooter(excel, &spreadsheetRow, missingCars)
+	htmlNotClassified(html, event.NotClassified)
+	textReasons(txt, hNotClassified, event.NotClassified, event.LongestNameLen)
+	excelReasons(excel, &spreadsheetRow, hNotClassified, event.NotClassified)
+
+	htmlReasons(html, hIneligible, event.Ineligible)
+	textReasons(txt, hIneligible, event.Ineligible, event.LongestNameLen)
+	excelReasons(excel, &spreadsheetRow, hIneligible, event.Ineligible)
+
+	if cfg.Progression {
+		htmlProgression(html, drivers)
+		excelProgression(excel, drivers)
+	}
+
+	if cfg.Analysis {
+		analyses := analyse(drivers)
+		htmlAnalysis(html, analyses)
+		textAnalysis(txt, analyses, event.LongestNameLen)
+		excelAnalysis(excel, &spreadsheetRow, analyses)
+	}
+
+	awards := calcAwards(drivers)
+	htmlAwards(html, awards)
+	textAwards(txt, awards)
+	excelAwards(excel, &spreadsheetRow, awards)
+
+	// Drivers are listed in the same order as the results.
+	all := append(append(append([]Driver{}, drivers...), event.NotClassified...), event.Ineligible...)
+	if cfg.PerRun {
+		htmlRuns(html, all)
+		textRuns(txt, all, event.LongestNameLen)
+	}
+	excelRuns(excel, all)
+	excelLaps(excel, all)
+
+	htmlDiagnostics(html, event.Diagnostics)
+	textDiagnostics(txt, event.Diagnostics)
+	excelDiagnostics(excel, &spreadsheetRow, event.Diagnostics)
+
+	htmlFooter(html, hMissing, event.Missing)
+	textFooter(txt, hMissing, event.Missing)
+	excelFooter(excel, &spreadsheetRow, hMissing, event.Missing)
+
+	htmlFooter(html, hAbsent, event.Absent)
+	textFooter(txt, hAbsent, event.Absent)
+	excelFooter(excel, &spreadsheetRow, hAbsent, event.Absent)
 
 	// Print text output to screen.
 	fmt.Println(txt.String())
 
-	fileName := time.Now().Format("results-2006-01-02 3;4;05")
+	fileName := event.Meta.fileName()
 
 	checkErr(ioutil.WriteFile(fileName+".txt", txt.Bytes(), filePermission))
 	checkErr(ioutil.WriteFile(fileName+".html", html.Bytes(), filePermission))
 	checkErr(excel.SaveAs(fileName + ".xlsx"))
+	checkErr(ioutil.WriteFile(fileName+" laps.csv", csvLaps(all), filePermission))
 }
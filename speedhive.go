@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// speedhiveSession is a session saved from MyLaps Speedhive, containing each competitor's laps.
+type speedhiveSession struct {
+	Name string         `json:"name"`
+	Rows []speedhiveRow `json:"rows"`
+}
+
+type speedhiveRow struct {
+	StartNumber string         `json:"startNumber"`
+	Name        string         `json:"name"`
+	Laps        []speedhiveLap `json:"laps"`
+}
+
+type speedhiveLap struct {
+	LapNumber uint   `json:"lapNumber"`
+	LapTime   string `json:"lapTime"` // Like "1:05.432".
+}
+
+// parseSpeedhive returns the session name and laps in a saved Speedhive JSON session file of a single run.
+func parseSpeedhive(src []byte, run uint) (session string, laps []timedLap, err error) {
+	var s speedhiveSession
+	if err = json.Unmarshal(src, &s); err != nil {
+		return "", nil, fmt.Errorf("unable to read the Speedhive session: %w", err)
+	}
+
+	if len(s.Rows) == 0 {
+		return "", nil, fmt.Errorf("no competitors found in the Speedhive session, expected %q", "rows")
+	}
+	for _, row := range s.Rows {
+		for _, l := range row.Laps {
+			t := timedLap{RaceNumber: row.StartNumber, Name: row.Name, Run: run, Number: l.LapNumber}
+			// Laps without a valid time are excluded.
+			t.Time, _ = parseLapTime(l.LapTime) //nolint:errcheck // Excluded when invalid.
+			laps = append(laps, t)
+		}
+	}
+
+	if len(laps) == 0 {
+		return "", nil, fmt.Errorf("no laps found in the Speedhive session, expected %q in each row", "laps")
+	}
+
+	return s.Name, laps, nil
+}
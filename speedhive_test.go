@@ -0,0 +1,37 @@
+package main
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSpeedhive(t *testing.T) {
+	src, err := ioutil.ReadFile("testdata/speedhive.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, laps, err := parseSpeedhive(src, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []timedLap{
+		{RaceNumber: "42", Name: "Joe Bloggs", Run: 1, Number: 1, Time: 72500 * time.Millisecond},
+		{RaceNumber: "42", Name: "Joe Bloggs", Run: 1, Number: 2, Time: 65432 * time.Millisecond},
+		{RaceNumber: "42", Name: "Joe Bloggs", Run: 1, Number: 3, Time: 66010 * time.Millisecond},
+		{RaceNumber: "512", Name: "Sam Smith", Run: 1, Number: 1, Time: 75 * time.Second},
+		{RaceNumber: "512", Name: "Sam Smith", Run: 1, Number: 2}, // In Pit is excluded.
+	}
+	if session != "Group A Run 1" || !reflect.DeepEqual(laps, want) {
+		t.Errorf("parseSpeedhive() = %q %+v, want %q %+v", session, laps, "Group A Run 1", want)
+	}
+
+	// Files that don't match the session layout are reported instead of importing no laps.
+	for _, src := range []string{`{}`, `{"name":"Run 1","rows":[]}`, `{"rows":[{"startNumber":"42","laps":[]}]}`, `{"sessions":[{"results":[]}]}`, `[1,2]`, `not json`} {
+		if _, laps, err := parseSpeedhive([]byte(src), 0); err == nil {
+			t.Errorf("parseSpeedhive(%s) = %d laps, want an error", src, len(laps))
+		}
+	}
+}
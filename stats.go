@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// consistency calculates the mean, median, standard deviation and coefficient of variation of laps counted, excluding Qualifying.
+func (driver *Driver) consistency() {
+	var seconds []float64
+	for i := range driver.Timing {
+		if driver.Timing[i].Status == lapCounted {
+			seconds = append(seconds, driver.Timing[i].Time.Seconds())
+		}
+	}
+
+	if len(seconds) == 0 {
+		return
+	}
+
+	var sum float64
+	for _, s := range seconds {
+		sum += s
+	}
+	driver.Mean = sum / float64(len(seconds))
+
+	driver.Median = median(seconds)
+
+	// The sample standard deviation requires at least two laps, the same as the spreadsheet STDEV.S function.
+	if len(seconds) < 2 {
+		return
+	}
+
+	var squares float64
+	for _, s := range seconds {
+		squares += (s - driver.Mean) * (s - driver.Mean)
+	}
+	driver.StdDev = math.Sqrt(squares / float64(len(seconds)-1))
+	driver.CV = driver.StdDev / driver.Mean * 100
+}
+
+// median returns the middle value of values, or the mean of the two middle values. Values are sorted in place.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
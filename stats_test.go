@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConsistency(t *testing.T) {
+	tests := []struct {
+		laps                 []Lap
+		mean, median, stdDev float64
+	}{
+		{laps: append(timing(0, 60), timing(1, 64, 66, 65, 67)...), mean: 65.5, median: 65.5, stdDev: math.Sqrt(5.0 / 3)},
+		{laps: append(timing(1, 64, 66), timing(2, 65)...), mean: 65, median: 65, stdDev: 1},
+		{laps: timing(1, 64), mean: 64, median: 64}, // A single lap has no standard deviation.
+		{laps: timing(0, 60)}, // Qualifying isn't included.
+	}
+
+	for i, test := range tests {
+		driver := Driver{Timing: test.laps}
+		driver.consistency()
+		if !near(driver.Mean, test.mean) || !near(driver.Median, test.median) || !near(driver.StdDev, test.stdDev) {
+			t.Errorf("%d: got mean %v, median %v and standard deviation %v, want %v, %v and %v", i, driver.Mean, driver.Median, driver.StdDev, test.mean, test.median, test.stdDev)
+		}
+		if test.mean > 0 && !near(driver.CV, test.stdDev/test.mean*100) {
+			t.Errorf("%d: got coefficient of variation %v, want %v", i, driver.CV, test.stdDev/test.mean*100)
+		}
+	}
+}
+
+// near returns true if a and b are equal, allowing for floating point rounding.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// nearMissDistance is the maximum quantity of characters that can differ between a similar driver name.
+const nearMissDistance = 2
+
+// Suggestion is a driver in the Natsoft results who might be a missing competitor.
+type Suggestion struct {
+	Entry      Entry  // The entered competitor without any results.
+	RaceNumber string // Racing number in the Natsoft results.
+	Name       string // Driver's name in the Natsoft results.
+	Reason     string // Why the driver might be the missing competitor.
+}
+
+// String returns the suggested driver and why they might be the missing competitor, like `74 Jack Black (transposed racing number)`.
+func (s *Suggestion) String() string {
+	return fmt.Sprintf("%s %s (%s)", s.RaceNumber, s.Name, s.Reason)
+}
+
+// suggestMatches returns the drivers who weren't entered in the event that might be the missing competitor.
+// Matches are a transposed racing number (47 vs 74), the same driver name under a different racing number, or a similar driver name.
+func suggestMatches(entry *Entry, others []Driver) (suggestions []Suggestion) {
+	name := normaliseName(entry.Name)
+	for i := range others {
+		var reasons []string
+		if isTransposed(entry.RaceNumber, others[i].RaceNumber) {
+			reasons = append(reasons, "transposed racing number")
+		}
+
+		if other := normaliseName(others[i].Name); name != "" && other != "" {
+			switch {
+			case name == other:
+				reasons = append(reasons, "same driver name")
+			case levenshtein(name, other) <= nearMissDistance:
+				reasons = append(reasons, "similar driver name")
+			}
+		}
+
+		if len(reasons) >= 1 {
+			suggestions = append(suggestions, Suggestion{
+				Entry:      *entry,
+				RaceNumber: others[i].RaceNumber,
+				Name:       others[i].Name,
+				Reason:     strings.Join(reasons, ", "),
+			})
+		}
+	}
+
+	return
+}
+
+// missing returns the entered competitor followed by any suggested matches, like `47 Jack Black - possibly 74 Jack Black (transposed racing number)`.
+func missing(entry *Entry, suggestions []Suggestion) string {
+	s := entry.String()
+	if len(suggestions) == 0 {
+		return s
+	}
+
+	possible := make([]string, len(suggestions))
+	for i := range suggestions {
+		possible[i] = suggestions[i].String()
+	}
+
+	return s + " - possibly " + strings.Join(possible, " or ")
+}
+
+// isTransposed returns true when b is a with two adjacent digits swapped, like 47 and 74.
+func isTransposed(a, b string) bool {
+	if len(a) != len(b) || a == b {
+		return false
+	}
+
+	for i := 0; i < len(a)-1; i++ {
+		if a[i] != b[i] {
+			return a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+		}
+	}
+
+	return false
+}
+
+// normaliseName returns the driver's name in lowercase with the words sorted, so `BLACK Jack` matches `Jack Black`.
+func normaliseName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
+
+// levenshtein returns the quantity of single character insertions, deletions or substitutions needed to change a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+// parseRemaps adds each remap in s to remaps, formatted as a comma separated list of the entered racing number and the racing number in the Natsoft results, like `47=74,12=21`.
+func parseRemaps(s string, remaps map[string]string) error {
+	for _, pair := range strings.Split(s, ",") {
+		entered, natsoft, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		entered, natsoft = strings.TrimSpace(entered), strings.TrimSpace(natsoft)
+		if !ok || entered == "" || natsoft == "" {
+			return fmt.Errorf("invalid remap %q, expected the entered racing number and the Natsoft racing number, like 47=74", pair)
+		}
+		remaps[entered] = natsoft
+	}
+
+	return nil
+}
+
+// confirmRemaps asks the user whether to score each suggested driver as the missing competitor and returns the accepted remaps,
+// keyed by the entered racing number.
+func confirmRemaps(suggestions []Suggestion) map[string]string {
+	remaps := map[string]string{}
+	used := map[string]bool{}
+	for i := range suggestions {
+		s := &suggestions[i]
+		if _, ok := remaps[s.Entry.RaceNumber]; ok || used[s.RaceNumber] {
+			continue
+		}
+
+		fmt.Printf("%s is missing. Score %s instead? [ y / n ]%s", s.Entry.String(), s.String(), newLine)
+		if yes(input()) {
+			remaps[s.Entry.RaceNumber] = s.RaceNumber
+			used[s.RaceNumber] = true
+		}
+	}
+
+	return remaps
+}
+
+// remap changes the racing number of each entered competitor in remaps to their racing number in the Natsoft results.
+func remap(entries []Entry, remaps map[string]string) {
+	entered := make([]string, 0, len(remaps))
+	for raceNumber := range remaps {
+		entered = append(entered, raceNumber)
+	}
+	sort.Strings(entered)
+
+	for _, raceNumber := range entered {
+		natsoft := remaps[raceNumber]
+		if indexOfEntry(entries, raceNumber) < 0 {
+			fmt.Println("Unable to remap racing number", raceNumber, "because it isn't entered in the event")
+			continue
+		}
+		if indexOfEntry(entries, natsoft) >= 0 {
+			fmt.Println("Unable to remap racing number", raceNumber, "because", natsoft, "is already entered in the event")
+			continue
+		}
+
+		// Every driver sharing the car is renumbered.
+		for i := range entries {
+			if strings.EqualFold(entries[i].RaceNumber, raceNumber) {
+				fmt.Printf("Scoring Natsoft racing number %s as entered competitor %s%s", natsoft, entries[i].String(), newLine)
+				entries[i].RaceNumber = natsoft
+			}
+		}
+	}
+}
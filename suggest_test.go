@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestMatches(t *testing.T) {
+	others := []Driver{
+		{RaceNumber: "74", Name: "Jack Black"},
+		{RaceNumber: "8", Name: "BLACK Jack"},
+		{RaceNumber: "9", Name: "Jak Blacke"},
+		{RaceNumber: "5", Name: "Ann Lee"},
+	}
+
+	entry := Entry{RaceNumber: "47", Name: "Jack Black"}
+	var got []string
+	for _, s := range suggestMatches(&entry, others) {
+		got = append(got, s.String())
+	}
+	want := []string{"74 Jack Black (transposed racing number, same driver name)", "8 BLACK Jack (same driver name)", "9 Jak Blacke (similar driver name)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestMatches() = %q, want %q", got, want)
+	}
+
+	// Without a name, only the racing number can be compared.
+	entry = Entry{RaceNumber: "57"}
+	if got := suggestMatches(&entry, others); len(got) != 0 {
+		t.Errorf("suggestMatches() = %v, want none", got)
+	}
+	if got := missing(&entry, nil); got != "57" {
+		t.Errorf("missing() = %q, want %q", got, "57")
+	}
+}
+
+func TestIsTransposed(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"47", "74", true},
+		{"123", "132", true},
+		{"123", "213", true},
+		{"123", "321", false},
+		{"47", "47", false},
+		{"47", "470", false},
+	}
+
+	for _, test := range tests {
+		if got := isTransposed(test.a, test.b); got != test.want {
+			t.Errorf("isTransposed(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"jack black", "jack black", 0},
+		{"jack black", "jak black", 1},
+		{"black jack", "blacke jak", 2},
+		{"", "ann", 3},
+	}
+
+	for _, test := range tests {
+		if got := levenshtein(test.a, test.b); got != test.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestRemap(t *testing.T) {
+	tests := []struct {
+		remaps map[string]string
+		want   []string
+	}{
+		{map[string]string{"47": "74"}, []string{"1", "74", "74", "12"}},
+		// A shared car is remapped for every driver.
+		{map[string]string{"47": "74", "12": "21"}, []string{"1", "74", "74", "21"}},
+		// A racing number already entered can't be used.
+		{map[string]string{"47": "1"}, []string{"1", "47", "47", "12"}},
+		{map[string]string{"1": "74", "47": "74"}, []string{"74", "47", "47", "12"}},
+		{map[string]string{"99": "98"}, []string{"1", "47", "47", "12"}},
+	}
+
+	for _, test := range tests {
+		entries := []Entry{
+			{RaceNumber: "1"},
+			{RaceNumber: "47", Name: "Jack Black", Runs: []uint{0, 1}},
+			{RaceNumber: "47", Name: "Jill Black", Runs: []uint{2}},
+			{RaceNumber: "12"},
+		}
+		remap(entries, test.remaps)
+
+		var got []string
+		for i := range entries {
+			got = append(got, entries[i].RaceNumber)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("remap(%v) = %v, want %v", test.remaps, got, test.want)
+		}
+	}
+}
+
+func TestParseRemaps(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    map[string]string
+		isError bool
+	}{
+		{s: "47=74", want: map[string]string{"47": "74"}},
+		{s: "47=74, 12 = 21", want: map[string]string{"47": "74", "12": "21"}},
+		{s: "47", isError: true},
+		{s: "47=", isError: true},
+		{s: "=74", isError: true},
+	}
+
+	for _, test := range tests {
+		remaps := map[string]string{}
+		err := parseRemaps(test.s, remaps)
+		if (err != nil) != test.isError {
+			t.Errorf("parseRemaps(%q) error = %v, want error %v", test.s, err, test.isError)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(remaps, test.want) {
+			t.Errorf("parseRemaps(%q) = %v, want %v", test.s, remaps, test.want)
+		}
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"strings"
 )
 
-func txtHeading(eventName string, driversQty, longestNameLen uint) *bytes.Buffer {
+func txtHeading(eventName string, meta *Meta, driversQty, longestNameLen, longestCarLen uint, sources bool) *bytes.Buffer {
+	if subtitle := meta.Subtitle(); subtitle != "" {
+		eventName += newLine + "   " + subtitle
+	}
+
 	//	-	Pad with spaces on the right rather than the left (left-justify the field).
 	//	*	Width or precision value taken from the integer preceding the one to format.
-	return bytes.NewBufferString(
-		fmt.Sprintf("   %s%sCompetitors: %d%s%-5s  %4s %-*s  %-10s    %-8s    %-10s    %-8s    %-10s    %-8s    %-9s    %-11s    %4s    %4s%[2]s",
+	txt := bytes.NewBufferString(
+		fmt.Sprintf("   %s%sCompetitors: %d%s%-5s  %4s %-*s  %-10s    %-8s    %-10s    %-8s    %-10s    %-8s    %-9s    %-11s",
 			eventName,
 			newLine,
 			driversQty,
@@ -24,17 +28,36 @@ func txtHeading(eventName string, driversQty, longestNameLen uint) *bytes.Buffer
 			hSlowest, hSeconds,
 			hAverage,
 			hPercentage,
-			hRuns,
-			hLaps,
 		))
+
+	if cfg.hasHandicaps() {
+		_, err := fmt.Fprintf(txt, "    %-*s    %-8s    %-11s", carWidth(longestCarLen), hCar, hHandicap, hScore)
+		checkErr(err)
+	}
+	if cfg.Consistency {
+		_, err := fmt.Fprintf(txt, "    %-8s    %-8s    %-8s    %6s", hMean, hMedian, hStdDev, hCV)
+		checkErr(err)
+	}
+
+	_, err := fmt.Fprintf(txt, "    %4s    %4s", hRuns, hLaps)
+	checkErr(err)
+	if sources {
+		_, err = fmt.Fprintf(txt, "    %s", hSource)
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(txt, newLine)
+	checkErr(err)
+
+	return txt
 }
 
-func textRow(txt io.Writer, d *Driver, ordinal string, longestNameLen uint) {
+// textRow adds the driver's results, with the sources of any laps not from the timing system when sources is true.
+func textRow(txt io.Writer, d *Driver, ordinal string, longestNameLen, longestCarLen uint, sources bool) {
 	/*	-	Pad with spaces on the right rather than the left (left-justify the field).
 		*	Width or precision value taken from the integer preceding the one to format.
 		%9f    width 9, default precision
 		%9.4f  width 9, precision 4 */
-	_, err := fmt.Fprintf(txt, "%-5s  %4s %-*s  %-10v    %-8.4f    %-10v    %-8.4f    %-10v    %-8.4f    %9.5f    %11.8f    %4d    %4d%s",
+	_, err := fmt.Fprintf(txt, "%-5s  %4s %-*s  %-10v    %-8.4f    %-10v    %-8.4f    %-10v    %-8.4f    %9.5f    %11.8f",
 		ordinal,
 		d.RaceNumber,
 		longestNameLen, d.Name,
@@ -43,16 +66,133 @@ func textRow(txt io.Writer, d *Driver, ordinal string, longestNameLen uint) {
 		d.Slowest, d.Slowest.Seconds(),
 		d.SlowAv,
 		d.Percentage,
-		d.Runs,
-		d.Laps,
-		newLine,
 	)
 	checkErr(err)
+
+	if cfg.hasHandicaps() {
+		_, err = fmt.Fprintf(txt, "    %-*s    %8.4f    %11.8f", carWidth(longestCarLen), d.Car, d.Handicap, d.Score)
+		checkErr(err)
+	}
+	if cfg.Consistency {
+		_, err = fmt.Fprintf(txt, "    %-8.4f    %-8.4f    %-8.4f    %6.2f", d.Mean, d.Median, d.StdDev, d.CV)
+		checkErr(err)
+	}
+
+	_, err = fmt.Fprintf(txt, "    %4d    %4d", d.Runs, d.Laps)
+	checkErr(err)
+	if sources {
+		_, err = fmt.Fprintf(txt, "    %s", d.Sources)
+		checkErr(err)
+	}
+	_, err = fmt.Fprint(txt, newLine)
+	checkErr(err)
+}
+
+// carWidth returns the car column width, which is at least as wide as its heading.
+func carWidth(longestCarLen uint) uint {
+	if l := uint(len(hCar)); longestCarLen < l {
+		return l
+	}
+	return longestCarLen
+}
+
+// textReasons lists the drivers excluded from the results under heading, with the reason why.
+func textReasons(txt io.Writer, heading string, drivers []Driver, longestNameLen uint) {
+	if len(drivers) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(txt, "%s%s%[1]s", newLine, heading)
+	checkErr(err)
+	for i := range drivers {
+		_, err = fmt.Fprintf(txt, "%4s %-*s  %s%s", drivers[i].RaceNumber, longestNameLen, drivers[i].Name, drivers[i].Reason, newLine)
+		checkErr(err)
+	}
+}
+
+func textRuns(txt io.Writer, drivers []Driver, longestNameLen uint) {
+	_, err := fmt.Fprintf(txt, "%s%s%[1]s", newLine, hRunStats)
+	checkErr(err)
+	_, err = fmt.Fprintf(txt, "%4s %-*s  %-7s  %4s    %-10s    %-10s    %-10s    %s%s", hRacingNumber, longestNameLen, hDriver, hRun, hLaps, hFastest, hSlowest, hAverageLap, hSource, newLine)
+	checkErr(err)
+
+	for i := range drivers {
+		for r := range drivers[i].RunStats {
+			if !drivers[i].drove(uint(r)) {
+				continue
+			}
+			run := &drivers[i].RunStats[r]
+			_, err = fmt.Fprintf(txt, "%4s %-*s  %-7s  %4d    %-10v    %-10v    %-10v    %s%s",
+				drivers[i].RaceNumber,
+				longestNameLen, drivers[i].Name,
+				runName(r),
+				run.Laps,
+				run.Fastest,
+				run.Slowest,
+				run.Average(),
+				run.Source,
+				newLine,
+			)
+			checkErr(err)
+		}
+	}
+}
+
+func textAnalysis(txt io.Writer, analyses []Analysis, longestNameLen uint) {
+	if len(analyses) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(txt, "%s%s%[1]s", newLine, hAnalysis)
+	checkErr(err)
+	_, err = fmt.Fprintf(txt, "%-5s  %4s %-*s  %-11s    %-11s    %-14s    %-14s%s", hPosition, hRacingNumber, longestNameLen, hDriver, hGapAhead, hGapLeader, hFastestNeeded, hSlowestNeeded, newLine)
+	checkErr(err)
+
+	for i := range analyses {
+		a := &analyses[i]
+		_, err = fmt.Fprintf(txt, "%-5s  %4s %-*s  %11.8f    %11.8f    %-14s    %-14s%s",
+			a.Driver.Ordinal(),
+			a.Driver.RaceNumber,
+			longestNameLen, a.Driver.Name,
+			a.GapAhead,
+			a.GapLeader,
+			needed(a.Fastest),
+			needed(a.Slowest),
+			newLine,
+		)
+		checkErr(err)
+	}
+}
+
+func textAwards(txt io.Writer, awards []Award) {
+	if len(awards) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(txt, "%s%s%[1]s", newLine, hAwards)
+	checkErr(err)
+	for i := range awards {
+		_, err = fmt.Fprintf(txt, "%-17s %s%s", awards[i].Heading, awards[i].String(), newLine)
+		checkErr(err)
+	}
+}
+
+func textDiagnostics(txt io.Writer, diagnostics []Diagnostic) {
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	_, err := fmt.Fprintf(txt, "%s%s%[1]s", newLine, hDiagnostics)
+	checkErr(err)
+	for i := range diagnostics {
+		_, err = fmt.Fprintf(txt, "%s%s", diagnostics[i].String(), newLine)
+		checkErr(err)
+	}
 }
 
-func textFooter(txt io.Writer, missingCars []string) {
-	if len(missingCars) >= 1 {
-		_, err := fmt.Fprintf(txt, "%s%s%[1]s%[3]s", newLine, hMissing, strings.Join(missingCars, newLine))
+func textFooter(txt io.Writer, heading string, cars []string) {
+	if len(cars) >= 1 {
+		_, err := fmt.Fprintf(txt, "%s%s%[1]s%[3]s", newLine, heading, strings.Join(cars, newLine))
 		checkErr(err)
 	}
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Timing results formats, see importTiming.
+const (
+	formatNatsoft   = "natsoft"
+	formatOrbits    = "orbits"
+	formatSpeedhive = "speedhive"
+	formatPassings  = "passings"
+	formatColumns   = "columns"
+)
+
+// timedLap is a single lap read from another timing system's results.
+type timedLap struct {
+	RaceNumber string
+	Name       string
+	Run        uint          // Zero based index, where zero is the Qualifying session.
+	Number     uint          // Lap number within the run reported by the timing system, used to order the laps.
+	Time       time.Duration // Zero when the lap is missing a time.
+	Note       string        // Why the lap is excluded despite having a time, like notePitStop.
+}
+
+// importTiming returns every car's laps and the event details from timing results files instead of the Natsoft results.
+// Each file contains a single run, in order starting with Qualifying, except for the formats holding the whole event:
+// Natsoft results, passings which contain every run found, and lap time spreadsheets with a run column.
+// The format is detected from the file when empty.
+func importTiming(fileNames []string, format string) (cars []Driver, meta Meta) {
+	var laps []timedLap
+	var run uint       // The next run to import.
+	var isNatsoft bool // Natsoft results were imported, which already contain every run.
+	for _, fileName := range fileNames {
+		fileName = strings.TrimSpace(fileName)
+		src, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			fmt.Println("Unable to read timing results", err)
+			continue
+		}
+
+		f := format
+		if f == "" {
+			if f, err = detectFormat(fileName, src); err != nil {
+				fmt.Println(fileName, err)
+				continue
+			}
+		}
+
+		// Natsoft results contain every run of the event, so they can't be combined with any other timing results.
+		if isNatsoft || f == formatNatsoft && run >= 1 {
+			fmt.Println("Ignoring", fileName, "because Natsoft results can't be combined with other timing results files")
+			continue
+		}
+
+		var sessions []string
+		var runLaps []timedLap
+		switch f {
+		case formatNatsoft:
+			isNatsoft = true
+			meta = eventMeta(src)
+			cars = append(cars, parseNatsoft(src)...)
+			fmt.Println("Using the Natsoft results from", fileName)
+			continue
+		case formatOrbits:
+			runLaps, err = parseOrbits(src, run)
+		case formatSpeedhive:
+			var session string
+			session, runLaps, err = parseSpeedhive(src, run)
+			sessions = append(sessions, session)
+		case formatColumns:
+			runLaps, sessions, err = parseLapColumns(src, run)
+		case formatPassings:
+			var starts []time.Duration
+			runLaps, starts, err = parsePassings(src, run)
+			for i := range starts {
+				sessions = append(sessions, sessionHeading(run+uint(i), starts[i]))
+			}
+			// Run start times are only known when every earlier run was also imported from passings.
+			if len(meta.Starts) == int(run) {
+				meta.Starts = append(meta.Starts, starts...)
+			}
+		default:
+			err = fmt.Errorf("unknown timing results format %q, expected %s, %s, %s, %s or %s", f, formatNatsoft, formatOrbits, formatSpeedhive, formatPassings, formatColumns)
+		}
+		if err != nil {
+			fmt.Println(fileName, err)
+			continue
+		}
+
+		if len(sessions) == 0 || sessions[0] == "" {
+			sessions = []string{strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))}
+		}
+		if len(sessions) == 1 {
+			fmt.Printf("Using %d laps from %s as %s%s", len(runLaps), fileName, sessionName(run), newLine)
+		} else {
+			fmt.Printf("Using %d laps from %s as %s to %s%s", len(runLaps), fileName, sessionName(run), sessionName(run+uint(len(sessions))-1), newLine)
+		}
+		meta.Sessions = append(meta.Sessions, sessions...)
+		laps = append(laps, runLaps...)
+		run += uint(len(sessions))
+	}
+
+	cars = append(cars, carsFromLaps(laps)...)
+	meta.override()
+
+	return cars, meta
+}
+
+// detectFormat returns the timing results format of the file, or an error when the file doesn't match any format.
+func detectFormat(fileName string, src []byte) (string, error) {
+	switch {
+	case strings.EqualFold(filepath.Ext(fileName), ".json"):
+		return formatSpeedhive, nil
+	case reHasDrivers.Match(src):
+		return formatNatsoft, nil
+	case isLapColumns(src):
+		// Checked before passings, because the `lapColumns` setting names the exact headings.
+		return formatColumns, nil
+	case isPassings(src):
+		return formatPassings, nil
+	case isOrbits(src):
+		return formatOrbits, nil
+	}
+
+	return "", fmt.Errorf("unrecognised timing file, expected %s, %s, %s, %s or %s results. Set the format with the -format flag", formatNatsoft, formatOrbits, formatSpeedhive, formatPassings, formatColumns)
+}
+
+// carsFromLaps returns each car's laps in the same lap model as the Natsoft results, in order of first appearance.
+// Like Natsoft results, the first lap of each run after Qualifying is skipped as a formation lap.
+// Laps are numbered in order across the whole event, as Natsoft numbers its lap columns, so lap adjustments can refer to them.
+func carsFromLaps(laps []timedLap) (cars []Driver) {
+	// Sort each car's laps by run then lap number.
+	sortTimedLaps(laps)
+
+	for _, t := range laps {
+		i := indexOfRacingNum(cars, t.RaceNumber)
+		if i < 0 {
+			cars = append(cars, Driver{RaceNumber: t.RaceNumber, Name: t.Name})
+			i = len(cars) - 1
+		}
+		car := &cars[i]
+
+		lap := Lap{Run: t.Run, Number: 1, Natsoft: uint(len(car.Timing)) + 1, Time: t.Time}
+		isFirst := true
+		if l := len(car.Timing); l >= 1 && car.Timing[l-1].Run == t.Run {
+			lap.Number = car.Timing[l-1].Number + 1
+			isFirst = false
+		}
+
+		switch {
+		case t.Time <= 0:
+			lap.Status = lapExcluded
+		case isFirst && t.Run >= 1:
+			lap.Status = lapFormation
+		case t.Note != "":
+			lap.Status = lapExcluded
+			lap.Note = t.Note
+		case t.Run == 0:
+			lap.Status = lapQualifying
+		default:
+			lap.Status = lapCounted
+		}
+
+		car.Timing = append(car.Timing, lap)
+	}
+
+	return cars
+}
+
+// sortTimedLaps sorts the laps by run and lap number, keeping each car's laps in the order they were listed when equal.
+func sortTimedLaps(laps []timedLap) {
+	sort.SliceStable(laps, func(i, j int) bool {
+		if laps[i].Run != laps[j].Run {
+			return laps[i].Run < laps[j].Run
+		}
+		return laps[i].Number < laps[j].Number
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportTimingNatsoftMix(t *testing.T) {
+	natsoft := filepath.Join(t.TempDir(), "natsoft.txt")
+	if err := ioutil.WriteFile(natsoft, []byte(natsoftResults), filePermission); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		files    []string
+		cars     int
+		sessions int
+	}{
+		{[]string{natsoft}, 2, 0},
+		{[]string{"testdata/orbits.csv", "testdata/speedhive.json"}, 2, 2},
+		// Natsoft results can't be combined with other timing results.
+		{[]string{natsoft, "testdata/orbits.csv"}, 2, 0},
+		{[]string{"testdata/orbits.csv", natsoft}, 2, 1},
+		{[]string{natsoft, natsoft}, 2, 0},
+	}
+
+	for _, test := range tests {
+		cars, meta := importTiming(test.files, "")
+		if len(cars) != test.cars || len(meta.Sessions) != test.sessions {
+			t.Errorf("importTiming(%q) = %d cars and %d sessions, want %d cars and %d sessions", test.files, len(cars), len(meta.Sessions), test.cars, test.sessions)
+		}
+	}
+}
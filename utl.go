@@ -3,12 +3,13 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 // hasRacingNum returns true if raceNumber is one of the drivers racing number.
-func hasRacingNum(drivers []Driver, raceNumber []byte) bool {
+func hasRacingNum(drivers []Driver, raceNumber string) bool {
 	for i := range drivers {
-		if bytes.EqualFold([]byte(drivers[i].RaceNumber), raceNumber) {
+		if strings.EqualFold(drivers[i].RaceNumber, raceNumber) {
 			return true
 		}
 	}
@@ -16,14 +17,27 @@ func hasRacingNum(drivers []Driver, raceNumber []byte) bool {
 	return false
 }
 
-// has checks if item is within the list.
-func has(list [][]byte, item []byte) bool {
-	for i := range list {
-		if bytes.EqualFold(list[i], item) {
-			return true
+// indexOfRacingNum returns the index of the driver with raceNumber, or -1 when not found.
+func indexOfRacingNum(drivers []Driver, raceNumber string) int {
+	for i := range drivers {
+		if strings.EqualFold(drivers[i].RaceNumber, raceNumber) {
+			return i
 		}
 	}
-	return false
+
+	return -1
+}
+
+// indexOfDriver returns the index of the driver with the same racing number and name as d, or -1 when not found.
+// Drivers sharing a car have the same racing number.
+func indexOfDriver(drivers []Driver, d *Driver) int {
+	for i := range drivers {
+		if strings.EqualFold(drivers[i].RaceNumber, d.RaceNumber) && drivers[i].Name == d.Name {
+			return i
+		}
+	}
+
+	return -1
 }
 
 func checkErr(err error) {
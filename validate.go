@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+)
+
+// validateSessions compares each driver's runs with the rest of the field and returns a diagnostic for each driver out of line.
+// A stray missing lap marker in the middle of a run adds an extra run, which is the main sort key.
+func validateSessions(drivers []Driver) (diagnostics []Diagnostic) {
+	runs := make([]uint, len(drivers))
+	for i := range drivers {
+		runs[i] = drivers[i].Runs
+	}
+
+	eventRuns, qty := mode(runs, true)
+	//nolint:gomnd // Ignore hardcoded numbers
+	if qty < 2 {
+		return nil
+	}
+
+	for i := range drivers {
+		if drivers[i].Runs > eventRuns {
+			diagnostics = append(diagnostics, Diagnostic{
+				RaceNumber: drivers[i].RaceNumber,
+				Message:    fmt.Sprintf("completed %d runs, but the event had %d runs. Check for a missing lap marker splitting a run", drivers[i].Runs, eventRuns),
+			})
+		}
+	}
+
+	// Compare the Natsoft lap number where each run started.
+	for run := uint(1); run <= eventRuns; run++ {
+		starts := make([]uint, len(drivers))
+		for i := range drivers {
+			starts[i] = drivers[i].runStart(run)
+		}
+
+		column, qty := mode(starts, false)
+		//nolint:gomnd // Ignore hardcoded numbers
+		if column == 0 || qty < 2 {
+			continue
+		}
+
+		for i := range drivers {
+			if starts[i] != 0 && starts[i] != column {
+				diagnostics = append(diagnostics, Diagnostic{
+					RaceNumber: drivers[i].RaceNumber,
+					Lap:        starts[i],
+					Message:    fmt.Sprintf("run %d started, but most drivers started run %d at lap %d", run, run, column),
+				})
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// runStart returns the Natsoft lap number where the run started, or zero when the driver didn't complete the run.
+func (driver *Driver) runStart(run uint) uint {
+	for i := range driver.Timing {
+		if driver.Timing[i].Run == run {
+			return driver.Timing[i].Natsoft
+		}
+	}
+
+	return 0
+}
+
+// mode returns the most common non-zero value and its quantity. When quantities are equal, the highest value is returned if highest is true, otherwise the lowest.
+func mode(values []uint, highest bool) (value, qty uint) {
+	counts := map[uint]uint{}
+	for _, v := range values {
+		if v != 0 {
+			counts[v]++
+		}
+	}
+
+	for v, c := range counts {
+		if c > qty || c == qty && (highest && v > value || !highest && v < value) {
+			value, qty = v, c
+		}
+	}
+
+	return value, qty
+}
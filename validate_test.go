@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// natsoftRuns returns a driver with the quantity of laps in each run, where each run starts at the Natsoft lap column listed in starts.
+func natsoftRuns(raceNumber string, starts []uint, laps int) Driver {
+	driver := Driver{RaceNumber: raceNumber}
+	for run, start := range starts {
+		seconds := make([]float64, laps)
+		for i := range seconds {
+			seconds[i] = 65
+		}
+		for _, lap := range timing(uint(run), seconds...) {
+			lap.Natsoft += start - 1
+			driver.Timing = append(driver.Timing, lap)
+		}
+		driver.Runs = uint(run)
+	}
+
+	return driver
+}
+
+func TestValidateSessions(t *testing.T) {
+	drivers := []Driver{
+		natsoftRuns("1", []uint{1, 6, 12}, 4),
+		natsoftRuns("2", []uint{1, 6, 12}, 4),
+		natsoftRuns("3", []uint{1, 6, 12}, 5),
+		natsoftRuns("4", []uint{1, 6, 9, 12}, 2), // A stray missing lap marker split run 1.
+		natsoftRuns("5", []uint{1, 12}, 4),       // Missed run 1.
+	}
+
+	var got []string
+	for _, d := range validateSessions(drivers) {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"car 4: completed 3 runs, but the event had 2 runs. Check for a missing lap marker splitting a run",
+		"car 5, lap 12: run 1 started, but most drivers started run 1 at lap 6",
+		"car 4, lap 9: run 2 started, but most drivers started run 2 at lap 12",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("validateSessions() =\n%q\nwant\n%q", got, want)
+	}
+
+	// Too few drivers to compare.
+	if got := validateSessions(drivers[3:4]); len(got) != 0 {
+		t.Errorf("validateSessions() with a single driver = %v, want no diagnostics", got)
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		values     []uint
+		highest    bool
+		value, qty uint
+	}{
+		{[]uint{2, 2, 3, 0, 0, 0}, true, 2, 2},
+		{[]uint{2, 3, 3, 2}, true, 3, 2},
+		{[]uint{2, 3, 3, 2}, false, 2, 2},
+		{[]uint{0, 0}, true, 0, 0},
+		{nil, false, 0, 0},
+	}
+
+	for _, test := range tests {
+		if value, qty := mode(test.values, test.highest); value != test.value || qty != test.qty {
+			t.Errorf("mode(%v, %v) = %d, %d, want %d, %d", test.values, test.highest, value, qty, test.value, test.qty)
+		}
+	}
+}